@@ -5,6 +5,7 @@ import (
 	"fit-byte/pkg/dotenv"
 	"log"
 	"os"
+	_ "time/tzdata" // user time zones are resolved on minimal images without zoneinfo
 
 	"github.com/labstack/echo/v4"
 )
@@ -17,19 +18,21 @@ func main() {
 	}
 
 	log := config.NewLogger()
+	pg := config.NewDatabase(log)
+	defer pg.Pool.Close()
+	activityTypes := config.NewActivityTypes(pg, log)
 	validator := config.NewValidator()
 	app := echo.New()
 	s3Uploader := config.NewS3Uploader(env)
-	pg := config.NewDatabase(log)
-	defer pg.Pool.Close()
 
 	config.Bootstrap(&config.BootstrapConfig{
-		App:        app,
-		DB:         pg,
-		Log:        log,
-		Validator:  validator,
-		S3Uploader: s3Uploader,
-		Env:        env,
+		App:           app,
+		DB:            pg,
+		Log:           log,
+		Validator:     validator,
+		ActivityTypes: activityTypes,
+		S3Uploader:    s3Uploader,
+		Env:           env,
 	})
 
 	PORT := os.Getenv("PORT")
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"context"
+	"fit-byte/internal/config"
+	"fit-byte/pkg/dotenv"
+	"log"
+
+	achievementRepository "fit-byte/internal/achievements/repository"
+	achievementUsecase "fit-byte/internal/achievements/usecase"
+	activityRepository "fit-byte/internal/activity/repository"
+)
+
+// backfill-achievements evaluates the achievement rules for every existing
+// user. Run it after adding a rule, awarding is idempotent.
+func main() {
+	_, err := dotenv.LoadEnv()
+	if err != nil {
+		log.Fatal("failed to load env", err.Error())
+		return
+	}
+
+	log := config.NewLogger()
+	pg := config.NewDatabase(log)
+	defer pg.Pool.Close()
+
+	activityRepo := activityRepository.NewActivityRepository(pg.Pool)
+	achievementRepo := achievementRepository.NewAchievementRepository(pg.Pool)
+	achievementUsecase := achievementUsecase.NewAchievementUseCase(*achievementRepo, *activityRepo)
+
+	awarded, err := achievementUsecase.BackfillAchievements(context.Background(), log)
+	if err != nil {
+		log.Fatal("failed to backfill achievements: ", err.Error())
+	}
+
+	log.Infof("backfill done, awarded %d achievements", awarded)
+}
@@ -0,0 +1,5 @@
+-- DROP calorie model columns
+ALTER TABLE activities
+    DROP COLUMN IF EXISTS calorie_model,
+    DROP COLUMN IF EXISTS met_value,
+    DROP COLUMN IF EXISTS weight_kg;
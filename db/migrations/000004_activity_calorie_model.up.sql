@@ -0,0 +1,5 @@
+-- Record how calories were estimated so they can be recomputed later
+ALTER TABLE activities
+    ADD COLUMN calorie_model VARCHAR(32) NOT NULL DEFAULT 'FLAT',
+    ADD COLUMN met_value NUMERIC(4, 1),
+    ADD COLUMN weight_kg NUMERIC(6, 2);
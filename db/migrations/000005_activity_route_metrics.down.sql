@@ -0,0 +1,4 @@
+-- DROP route metric columns
+ALTER TABLE activities
+    DROP COLUMN IF EXISTS distance_meters,
+    DROP COLUMN IF EXISTS elevation_gain_meters;
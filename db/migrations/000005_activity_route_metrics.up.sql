@@ -0,0 +1,4 @@
+-- Optional metrics computed from uploaded GPS workouts
+ALTER TABLE activities
+    ADD COLUMN distance_meters NUMERIC(10, 2),
+    ADD COLUMN elevation_gain_meters NUMERIC(8, 2);
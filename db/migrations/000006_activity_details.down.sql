@@ -0,0 +1,5 @@
+-- DROP heart rate and notes columns
+ALTER TABLE activities
+    DROP COLUMN IF EXISTS avg_heart_rate,
+    DROP COLUMN IF EXISTS max_heart_rate,
+    DROP COLUMN IF EXISTS notes;
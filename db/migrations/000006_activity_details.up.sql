@@ -0,0 +1,5 @@
+-- Optional heart rate and notes on activities
+ALTER TABLE activities
+    ADD COLUMN avg_heart_rate INT,
+    ADD COLUMN max_heart_rate INT,
+    ADD COLUMN notes TEXT;
@@ -0,0 +1,6 @@
+-- DROP trigger
+DROP TRIGGER IF EXISTS set_timestamp_users ON users CASCADE;
+
+-- DROP updated_at column
+ALTER TABLE users
+    DROP COLUMN IF EXISTS updated_at;
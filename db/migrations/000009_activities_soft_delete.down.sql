@@ -0,0 +1,5 @@
+-- DROP soft delete
+DROP INDEX IF EXISTS idx_activities_deleted_at;
+
+ALTER TABLE activities
+    DROP COLUMN IF EXISTS deleted_at;
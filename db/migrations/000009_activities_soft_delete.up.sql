@@ -0,0 +1,5 @@
+-- Soft delete activities so they can be restored from the trash
+ALTER TABLE activities
+    ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX idx_activities_deleted_at ON activities(deleted_at) WHERE deleted_at IS NOT NULL;
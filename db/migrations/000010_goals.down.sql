@@ -0,0 +1,10 @@
+-- DROP trigger
+DROP TRIGGER IF EXISTS set_timestamp_goals ON goals CASCADE;
+
+-- DROP goals
+DROP INDEX IF EXISTS idx_goals_user_id;
+DROP TABLE IF EXISTS goals CASCADE;
+
+-- DROP enum
+DROP TYPE IF EXISTS enum_goal_metrics CASCADE;
+DROP TYPE IF EXISTS enum_goal_periods CASCADE;
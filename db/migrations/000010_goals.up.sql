@@ -0,0 +1,24 @@
+-- Create enum
+CREATE TYPE enum_goal_metrics as ENUM ('DURATION', 'CALORIES', 'SESSIONS');
+CREATE TYPE enum_goal_periods as ENUM ('WEEK', 'MONTH');
+
+-- Create table goals
+CREATE TABLE goals (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    metric enum_goal_metrics NOT NULL,
+    period enum_goal_periods NOT NULL,
+    target INT NOT NULL CHECK (target > 0),
+    activity_type enum_activity_types,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_goals_user_id ON goals(user_id);
+
+-- Create triggers
+CREATE TRIGGER set_timestamp_goals
+    BEFORE UPDATE ON goals
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
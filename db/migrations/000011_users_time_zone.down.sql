@@ -0,0 +1,3 @@
+-- DROP time_zone column
+ALTER TABLE users
+    DROP COLUMN IF EXISTS time_zone;
@@ -0,0 +1,3 @@
+-- IANA time zone used to bucket activities into the user's local days
+ALTER TABLE users
+    ADD COLUMN time_zone VARCHAR(64) NOT NULL DEFAULT 'UTC';
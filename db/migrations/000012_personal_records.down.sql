@@ -0,0 +1,8 @@
+-- DROP trigger
+DROP TRIGGER IF EXISTS set_timestamp_personal_records ON personal_records CASCADE;
+
+-- DROP personal_records
+DROP TABLE IF EXISTS personal_records CASCADE;
+
+-- DROP enum
+DROP TYPE IF EXISTS enum_record_types CASCADE;
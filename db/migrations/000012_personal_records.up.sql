@@ -0,0 +1,25 @@
+-- Create enum
+CREATE TYPE enum_record_types as ENUM ('LONGEST_DURATION', 'MOST_CALORIES', 'MOST_WEEKLY_MINUTES');
+
+-- Create table personal_records
+CREATE TABLE personal_records (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    activity_type enum_activity_types NOT NULL,
+    record_type enum_record_types NOT NULL,
+    value INT NOT NULL,
+    activity_id BIGINT,
+    period_start DATE,
+    achieved_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (user_id, activity_type, record_type),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    FOREIGN KEY (activity_id) REFERENCES activities(id) ON DELETE SET NULL
+);
+
+-- Create triggers
+CREATE TRIGGER set_timestamp_personal_records
+    BEFORE UPDATE ON personal_records
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
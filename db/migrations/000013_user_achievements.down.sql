@@ -0,0 +1,2 @@
+-- DROP user_achievements
+DROP TABLE IF EXISTS user_achievements CASCADE;
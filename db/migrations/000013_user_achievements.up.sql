@@ -0,0 +1,8 @@
+-- Create table user_achievements
+CREATE TABLE user_achievements (
+    user_id BIGINT NOT NULL,
+    achievement_code VARCHAR(64) NOT NULL,
+    awarded_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (user_id, achievement_code),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
@@ -0,0 +1,12 @@
+-- DROP trigger
+DROP TRIGGER IF EXISTS set_timestamp_planned_workouts ON planned_workouts CASCADE;
+
+-- DROP planned_workout_completions
+DROP TABLE IF EXISTS planned_workout_completions CASCADE;
+
+-- DROP planned_workouts
+DROP INDEX IF EXISTS idx_planned_workouts_user_time;
+DROP TABLE IF EXISTS planned_workouts CASCADE;
+
+-- DROP enum
+DROP TYPE IF EXISTS enum_recurrences CASCADE;
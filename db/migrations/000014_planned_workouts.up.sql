@@ -0,0 +1,37 @@
+-- Create enum
+CREATE TYPE enum_recurrences as ENUM ('NONE', 'DAILY', 'WEEKDAYS', 'WEEKLY');
+
+-- Create table planned_workouts
+CREATE TABLE planned_workouts (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    activity_type enum_activity_types NOT NULL,
+    scheduled_at TIMESTAMPTZ NOT NULL,
+    duration_in_minutes INT NOT NULL,
+    recurrence enum_recurrences NOT NULL DEFAULT 'NONE',
+    days_of_week INT[] NOT NULL DEFAULT '{}',
+    recurrence_until TIMESTAMPTZ,
+    notes TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_planned_workouts_user_time ON planned_workouts(user_id, scheduled_at);
+
+-- Create table planned_workout_completions
+CREATE TABLE planned_workout_completions (
+    planned_workout_id BIGINT NOT NULL,
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    activity_id BIGINT,
+    completed_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (planned_workout_id, occurrence_at),
+    FOREIGN KEY (planned_workout_id) REFERENCES planned_workouts(id) ON DELETE CASCADE,
+    FOREIGN KEY (activity_id) REFERENCES activities(id) ON DELETE SET NULL
+);
+
+-- Create triggers
+CREATE TRIGGER set_timestamp_planned_workouts
+    BEFORE UPDATE ON planned_workouts
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
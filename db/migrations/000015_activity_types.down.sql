@@ -0,0 +1,31 @@
+-- Recreate enum. Converting back fails while custom activity types are in use.
+CREATE TYPE enum_activity_types as ENUM (
+    'Walking',
+    'Yoga',
+    'Stretching',
+    'Cycling',
+    'Swimming',
+    'Dancing',
+    'Hiking',
+    'Running',
+    'HIIT',
+    'JumpRope'
+);
+
+ALTER TABLE activities ALTER COLUMN activity_type TYPE enum_activity_types USING activity_type::enum_activity_types;
+ALTER TABLE goals ALTER COLUMN activity_type TYPE enum_activity_types USING activity_type::enum_activity_types;
+ALTER TABLE personal_records ALTER COLUMN activity_type TYPE enum_activity_types USING activity_type::enum_activity_types;
+ALTER TABLE planned_workouts ALTER COLUMN activity_type TYPE enum_activity_types USING activity_type::enum_activity_types;
+
+ALTER TABLE goals DROP COLUMN IF EXISTS category;
+
+-- DROP trigger
+DROP TRIGGER IF EXISTS set_timestamp_activity_types ON activity_types CASCADE;
+
+-- DROP activity_types
+DROP INDEX IF EXISTS idx_activity_types_global_name;
+DROP INDEX IF EXISTS idx_activity_types_user_name;
+DROP TABLE IF EXISTS activity_types CASCADE;
+
+-- DROP enum
+DROP TYPE IF EXISTS enum_activity_categories CASCADE;
@@ -0,0 +1,51 @@
+-- Create enum
+CREATE TYPE enum_activity_categories as ENUM ('CARDIO', 'STRENGTH', 'FLEXIBILITY');
+
+-- Create table activity_types. Global types have no user_id, custom types
+-- belong to a single user. Calories are estimated from met_value when the
+-- user's weight is known and from calories_per_minute otherwise.
+CREATE TABLE activity_types (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT,
+    name VARCHAR(50) NOT NULL,
+    category enum_activity_categories NOT NULL,
+    calories_per_minute INT,
+    met_value NUMERIC(4, 1),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK (calories_per_minute IS NOT NULL OR met_value IS NOT NULL),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE UNIQUE INDEX idx_activity_types_global_name ON activity_types(lower(name)) WHERE user_id IS NULL;
+CREATE UNIQUE INDEX idx_activity_types_user_name ON activity_types(user_id, lower(name)) WHERE user_id IS NOT NULL;
+
+-- Create triggers
+CREATE TRIGGER set_timestamp_activity_types
+    BEFORE UPDATE ON activity_types
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+-- Seed the global types from enum_activity_types
+INSERT INTO activity_types (name, category, calories_per_minute, met_value) VALUES
+    ('Walking', 'CARDIO', 4, 3.5),
+    ('Yoga', 'FLEXIBILITY', 4, 2.5),
+    ('Stretching', 'FLEXIBILITY', 4, 2.3),
+    ('Cycling', 'CARDIO', 8, 7.5),
+    ('Swimming', 'CARDIO', 8, 6.0),
+    ('Dancing', 'CARDIO', 8, 5.0),
+    ('Hiking', 'CARDIO', 10, 6.0),
+    ('Running', 'CARDIO', 10, 9.8),
+    ('HIIT', 'CARDIO', 10, 8.0),
+    ('JumpRope', 'CARDIO', 10, 11.0);
+
+-- Store activity types by name instead of the enum
+ALTER TABLE activities ALTER COLUMN activity_type TYPE VARCHAR(50) USING activity_type::text;
+ALTER TABLE goals ALTER COLUMN activity_type TYPE VARCHAR(50) USING activity_type::text;
+ALTER TABLE personal_records ALTER COLUMN activity_type TYPE VARCHAR(50) USING activity_type::text;
+ALTER TABLE planned_workouts ALTER COLUMN activity_type TYPE VARCHAR(50) USING activity_type::text;
+
+-- Goals can count a whole category instead of a single type
+ALTER TABLE goals ADD COLUMN category enum_activity_categories;
+
+DROP TYPE IF EXISTS enum_activity_types CASCADE;
@@ -0,0 +1,8 @@
+-- DROP activity_exercise_sets
+DROP TABLE IF EXISTS activity_exercise_sets CASCADE;
+
+-- DROP activity_exercises
+DROP TABLE IF EXISTS activity_exercises CASCADE;
+
+-- DELETE the seeded strength type
+DELETE FROM activity_types WHERE user_id IS NULL AND name = 'WeightTraining';
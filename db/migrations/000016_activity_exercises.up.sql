@@ -0,0 +1,26 @@
+-- Create table activity_exercises. Set weights are stored in the weight unit
+-- the user had when the session was logged.
+CREATE TABLE activity_exercises (
+    id BIGSERIAL PRIMARY KEY,
+    activity_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    name VARCHAR(100) NOT NULL,
+    weight_unit enum_weight_units NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    UNIQUE (activity_id, position),
+    FOREIGN KEY (activity_id) REFERENCES activities(id) ON DELETE CASCADE
+);
+
+-- Create table activity_exercise_sets
+CREATE TABLE activity_exercise_sets (
+    exercise_id BIGINT NOT NULL,
+    position INT NOT NULL,
+    reps INT NOT NULL CHECK (reps > 0),
+    weight NUMERIC(7, 2) NOT NULL CHECK (weight >= 0),
+    PRIMARY KEY (exercise_id, position),
+    FOREIGN KEY (exercise_id) REFERENCES activity_exercises(id) ON DELETE CASCADE
+);
+
+-- Seed a global strength type
+INSERT INTO activity_types (name, category, calories_per_minute, met_value) VALUES
+    ('WeightTraining', 'STRENGTH', 6, 5.0);
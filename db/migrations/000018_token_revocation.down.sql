@@ -0,0 +1,17 @@
+-- Restore the generic users trigger
+DROP TRIGGER IF EXISTS set_timestamp_users ON users;
+
+CREATE TRIGGER set_timestamp_users
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp();
+
+DROP FUNCTION IF EXISTS trigger_set_timestamp_users();
+
+-- DROP revoked_tokens
+DROP INDEX IF EXISTS idx_revoked_tokens_expires_at;
+DROP TABLE IF EXISTS revoked_tokens CASCADE;
+
+-- DROP token_version column
+ALTER TABLE users
+    DROP COLUMN IF EXISTS token_version;
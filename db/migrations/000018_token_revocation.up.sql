@@ -0,0 +1,36 @@
+-- Tokens signed with a lower version than the user's are rejected
+ALTER TABLE users
+    ADD COLUMN token_version INT NOT NULL DEFAULT 0;
+
+-- Create table revoked_tokens. Rows are only needed until the access token
+-- would have expired anyway.
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
+
+-- updated_at drives the profile ETag, so only profile changes may bump it.
+-- token_version is bumped by "log out everywhere" and must not invalidate
+-- the If-Match of a pending profile update.
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_users()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF (to_jsonb(NEW) - 'updated_at' - 'token_version')
+     IS DISTINCT FROM (to_jsonb(OLD) - 'updated_at' - 'token_version') THEN
+    NEW.updated_at = NOW();
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS set_timestamp_users ON users;
+
+CREATE TRIGGER set_timestamp_users
+    BEFORE UPDATE ON users
+    FOR EACH ROW
+    EXECUTE FUNCTION trigger_set_timestamp_users();
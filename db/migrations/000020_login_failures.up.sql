@@ -0,0 +1,10 @@
+-- Create table login_failures. key names what is throttled, such as
+-- "account:<email>", "ip:<address>", "user:<id>", "verify:user:<id>" or
+-- "reset:account:<email>", so unknown emails are throttled exactly like
+-- registered ones.
+CREATE TABLE login_failures (
+    key VARCHAR(400) PRIMARY KEY,
+    failures INT NOT NULL,
+    last_failed_at TIMESTAMPTZ NOT NULL,
+    locked_until TIMESTAMPTZ
+);
@@ -0,0 +1,7 @@
+-- DROP email_verification_tokens
+DROP INDEX IF EXISTS idx_email_verification_tokens_user_id;
+DROP TABLE IF EXISTS email_verification_tokens CASCADE;
+
+-- DROP email_verified_at column
+ALTER TABLE users
+    DROP COLUMN IF EXISTS email_verified_at;
@@ -0,0 +1,19 @@
+-- Accounts that existed before verification was introduced are treated as
+-- verified, so they keep full access.
+ALTER TABLE users
+    ADD COLUMN email_verified_at TIMESTAMPTZ;
+
+UPDATE users SET email_verified_at = NOW();
+
+-- Create table email_verification_tokens
+CREATE TABLE email_verification_tokens (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_email_verification_tokens_user_id ON email_verification_tokens(user_id, created_at);
@@ -0,0 +1,24 @@
+-- Only token_version is ignored again
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_users()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF (to_jsonb(NEW) - 'updated_at' - 'token_version')
+     IS DISTINCT FROM (to_jsonb(OLD) - 'updated_at' - 'token_version') THEN
+    NEW.updated_at = NOW();
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- DROP login_challenges
+DROP INDEX IF EXISTS idx_login_challenges_user_id;
+DROP TABLE IF EXISTS login_challenges CASCADE;
+
+-- DROP totp_recovery_codes
+DROP TABLE IF EXISTS totp_recovery_codes CASCADE;
+
+-- DROP totp columns
+ALTER TABLE users
+    DROP COLUMN IF EXISTS totp_last_step,
+    DROP COLUMN IF EXISTS totp_enabled_at,
+    DROP COLUMN IF EXISTS totp_secret;
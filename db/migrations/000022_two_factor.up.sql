@@ -0,0 +1,45 @@
+-- TOTP secret sealed with AES-GCM. It is pending until totp_enabled_at is
+-- set by confirming a first code. totp_last_step stops a code being replayed.
+ALTER TABLE users
+    ADD COLUMN totp_secret BYTEA,
+    ADD COLUMN totp_enabled_at TIMESTAMPTZ,
+    ADD COLUMN totp_last_step BIGINT;
+
+-- Create table totp_recovery_codes
+CREATE TABLE totp_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    code_hash CHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    UNIQUE (user_id, code_hash)
+);
+
+-- Create table login_challenges
+CREATE TABLE login_challenges (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL,
+    token_hash CHAR(64) NOT NULL UNIQUE,
+    attempts INT NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+CREATE INDEX idx_login_challenges_user_id ON login_challenges(user_id);
+
+-- Two-factor state lives on the users row but is not part of the profile.
+-- Every login with a TOTP code writes totp_last_step, which must not change
+-- the profile ETag either.
+CREATE OR REPLACE FUNCTION trigger_set_timestamp_users()
+RETURNS TRIGGER AS $$
+BEGIN
+  IF (to_jsonb(NEW) - 'updated_at' - 'token_version' - 'totp_secret' - 'totp_enabled_at' - 'totp_last_step')
+     IS DISTINCT FROM (to_jsonb(OLD) - 'updated_at' - 'token_version' - 'totp_secret' - 'totp_enabled_at' - 'totp_last_step') THEN
+    NEW.updated_at = NOW();
+  END IF;
+  RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
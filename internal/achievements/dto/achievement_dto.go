@@ -0,0 +1,11 @@
+package dto
+
+type AchievementResponse struct {
+	Code        string  `json:"code"`
+	Name        string  `json:"name"`
+	Description string  `json:"description"`
+	Target      int     `json:"target"`
+	Current     int     `json:"current"`
+	Achieved    bool    `json:"achieved"`
+	AwardedAt   *string `json:"awardedAt"`
+}
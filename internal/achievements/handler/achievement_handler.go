@@ -0,0 +1,35 @@
+package handler
+
+import (
+	"net/http"
+
+	"fit-byte/internal/achievements/model/converter"
+	"fit-byte/internal/achievements/usecase"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AchievementHandler struct {
+	UseCase usecase.AchievementUseCase
+}
+
+func NewAchievementHandler(useCase usecase.AchievementUseCase) *AchievementHandler {
+	return &AchievementHandler{
+		UseCase: useCase,
+	}
+}
+
+func (c *AchievementHandler) GetAchievements(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	achievements, err := c.UseCase.GetAchievements(ctx.Request().Context(), userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToAchievementResponseList(achievements)
+
+	return ctx.JSON(http.StatusOK, response)
+}
@@ -0,0 +1,48 @@
+package model
+
+import (
+	"time"
+
+	activityModel "fit-byte/internal/activity/model"
+)
+
+// ActivityTotals are the all time aggregates achievement rules are evaluated on.
+type ActivityTotals struct {
+	SessionCount           int
+	TotalDurationInMinutes int
+	TotalCaloriesBurned    int
+	ByType                 map[activityModel.ActivityTypeEnum]activityModel.ActivityTotal
+}
+
+func NewActivityTotals(totals []activityModel.ActivityTotal) ActivityTotals {
+	t := ActivityTotals{ByType: make(map[activityModel.ActivityTypeEnum]activityModel.ActivityTotal, len(totals))}
+	for _, total := range totals {
+		t.SessionCount += total.SessionCount
+		t.TotalDurationInMinutes += total.TotalDurationInMinutes
+		t.TotalCaloriesBurned += total.TotalCaloriesBurned
+		t.ByType[total.ActivityType] = total
+	}
+	return t
+}
+
+// Rule is an achievement awarded once Progress reaches Target.
+type Rule struct {
+	Code        string
+	Name        string
+	Description string
+	Target      int
+	Progress    func(ActivityTotals) int
+}
+
+type UserAchievement struct {
+	UserId          int
+	AchievementCode string
+	AwardedAt       time.Time
+}
+
+// AchievementProgress is a rule with how far a user is towards it.
+type AchievementProgress struct {
+	Rule
+	Current   int
+	AwardedAt *time.Time
+}
@@ -0,0 +1,33 @@
+package converter
+
+import (
+	"fit-byte/internal/achievements/dto"
+	"fit-byte/internal/achievements/model"
+	"fit-byte/pkg/helper"
+)
+
+func ToAchievementResponse(achievement model.AchievementProgress) dto.AchievementResponse {
+	var awardedAt *string
+	if achievement.AwardedAt != nil {
+		formatted := helper.FormatTimeToUTC(*achievement.AwardedAt)
+		awardedAt = &formatted
+	}
+
+	return dto.AchievementResponse{
+		Code:        achievement.Code,
+		Name:        achievement.Name,
+		Description: achievement.Description,
+		Target:      achievement.Target,
+		Current:     achievement.Current,
+		Achieved:    achievement.AwardedAt != nil,
+		AwardedAt:   awardedAt,
+	}
+}
+
+func ToAchievementResponseList(achievements []model.AchievementProgress) []dto.AchievementResponse {
+	responses := make([]dto.AchievementResponse, len(achievements))
+	for i, achievement := range achievements {
+		responses[i] = ToAchievementResponse(achievement)
+	}
+	return responses
+}
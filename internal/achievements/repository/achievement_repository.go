@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"fit-byte/internal/achievements/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type AchievementRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewAchievementRepository(pool *pgxpool.Pool) *AchievementRepository {
+	return &AchievementRepository{pool: pool}
+}
+
+func collectUserAchievements(rows pgx.Rows) ([]model.UserAchievement, error) {
+	defer rows.Close()
+	var items []model.UserAchievement
+	for rows.Next() {
+		var i model.UserAchievement
+		if err := rows.Scan(
+			&i.UserId,
+			&i.AchievementCode,
+			&i.AwardedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const awardAchievements = `-- name: AwardAchievements :many
+INSERT INTO user_achievements (user_id, achievement_code)
+SELECT $1::bigint, code FROM unnest($2::text[]) AS code
+ON CONFLICT (user_id, achievement_code) DO NOTHING
+RETURNING user_id, achievement_code, awarded_at
+`
+
+// AwardAchievements stores the given achievements and returns only those the
+// user did not have yet.
+func (r *AchievementRepository) AwardAchievements(ctx context.Context, userId int, codes []string) ([]model.UserAchievement, error) {
+	rows, err := r.pool.Query(ctx, awardAchievements, userId, codes)
+	if err != nil {
+		return nil, err
+	}
+	return collectUserAchievements(rows)
+}
+
+const listUserAchievements = `-- name: ListUserAchievements :many
+SELECT user_id, achievement_code, awarded_at FROM user_achievements
+WHERE (user_id = $1::bigint)
+ORDER BY awarded_at
+`
+
+func (r *AchievementRepository) ListUserAchievements(ctx context.Context, userId int) ([]model.UserAchievement, error) {
+	rows, err := r.pool.Query(ctx, listUserAchievements, userId)
+	if err != nil {
+		return nil, err
+	}
+	return collectUserAchievements(rows)
+}
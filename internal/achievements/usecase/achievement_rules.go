@@ -0,0 +1,83 @@
+package usecase
+
+import (
+	"fit-byte/internal/achievements/model"
+	activityModel "fit-byte/internal/activity/model"
+)
+
+func sessionsOf(activityType activityModel.ActivityTypeEnum) func(model.ActivityTotals) int {
+	return func(t model.ActivityTotals) int {
+		return t.ByType[activityType].SessionCount
+	}
+}
+
+// explorerActivityTypes are the 10 activity types the API launched with. The
+// Explorer achievement is defined over exactly these, so types added later do
+// not take it away from users who already earned it.
+var explorerActivityTypes = []activityModel.ActivityTypeEnum{
+	activityModel.ActivityTypeEnumWalking,
+	activityModel.ActivityTypeEnumYoga,
+	activityModel.ActivityTypeEnumStretching,
+	activityModel.ActivityTypeEnumCycling,
+	activityModel.ActivityTypeEnumSwimming,
+	activityModel.ActivityTypeEnumDancing,
+	activityModel.ActivityTypeEnumHiking,
+	activityModel.ActivityTypeEnumRunning,
+	activityModel.ActivityTypeEnumHIIT,
+	activityModel.ActivityTypeEnumJumpRope,
+}
+
+// achievementRules are evaluated in order, which is also the listing order.
+// Codes are stored on awarded achievements and must never change.
+var achievementRules = []model.Rule{
+	{
+		Code:        "FIRST_ACTIVITY",
+		Name:        "First Step",
+		Description: "Log your first activity",
+		Target:      1,
+		Progress:    func(t model.ActivityTotals) int { return t.SessionCount },
+	},
+	{
+		Code:        "FIFTY_SESSIONS",
+		Name:        "Regular",
+		Description: "Log 50 activities",
+		Target:      50,
+		Progress:    func(t model.ActivityTotals) int { return t.SessionCount },
+	},
+	{
+		Code:        "TEN_HIIT_SESSIONS",
+		Name:        "Interval Hero",
+		Description: "Complete 10 HIIT sessions",
+		Target:      10,
+		Progress:    sessionsOf(activityModel.ActivityTypeEnumHIIT),
+	},
+	{
+		Code:        "HUNDRED_HOURS",
+		Name:        "Centurion",
+		Description: "Spend 100 hours being active",
+		Target:      100 * 60,
+		Progress:    func(t model.ActivityTotals) int { return t.TotalDurationInMinutes },
+	},
+	{
+		Code:        "TEN_THOUSAND_CALORIES",
+		Name:        "Furnace",
+		Description: "Burn 10,000 kcal in total",
+		Target:      10000,
+		Progress:    func(t model.ActivityTotals) int { return t.TotalCaloriesBurned },
+	},
+	{
+		Code:        "ALL_ACTIVITY_TYPES",
+		Name:        "Explorer",
+		Description: "Try each of the 10 original activity types",
+		Target:      len(explorerActivityTypes),
+		Progress: func(t model.ActivityTotals) int {
+			tried := 0
+			for _, activityType := range explorerActivityTypes {
+				if t.ByType[activityType].SessionCount > 0 {
+					tried++
+				}
+			}
+			return tried
+		},
+	},
+}
@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+
+	"fit-byte/internal/achievements/model"
+	"fit-byte/internal/achievements/repository"
+	activityRepository "fit-byte/internal/activity/repository"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type AchievementUseCase struct {
+	achievementRepo repository.AchievementRepository
+	activityRepo    activityRepository.ActivityRepository
+}
+
+func NewAchievementUseCase(achievementRepo repository.AchievementRepository, activityRepo activityRepository.ActivityRepository) *AchievementUseCase {
+	return &AchievementUseCase{
+		achievementRepo: achievementRepo,
+		activityRepo:    activityRepo,
+	}
+}
+
+func (c *AchievementUseCase) activityTotals(ctx context.Context, userId int) (model.ActivityTotals, error) {
+	totals, err := c.activityRepo.ListActivityTotals(ctx, userId)
+	if err != nil {
+		return model.ActivityTotals{}, errors.Wrap(err, "failed to get activity totals")
+	}
+
+	return model.NewActivityTotals(totals), nil
+}
+
+// EvaluateAchievements awards every rule the user meets and returns the
+// achievements that were newly awarded. Achievements are never taken back.
+func (c *AchievementUseCase) EvaluateAchievements(ctx context.Context, userId int) ([]model.UserAchievement, error) {
+	totals, err := c.activityTotals(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	var codes []string
+	for _, rule := range achievementRules {
+		if rule.Progress(totals) >= rule.Target {
+			codes = append(codes, rule.Code)
+		}
+	}
+	if len(codes) == 0 {
+		return nil, nil
+	}
+
+	awarded, err := c.achievementRepo.AwardAchievements(ctx, userId, codes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to award achievements")
+	}
+
+	return awarded, nil
+}
+
+// GetAchievements lists every rule with the user's progress towards it.
+func (c *AchievementUseCase) GetAchievements(ctx context.Context, userId int) ([]model.AchievementProgress, error) {
+	totals, err := c.activityTotals(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	awarded, err := c.achievementRepo.ListUserAchievements(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get achievements")
+	}
+
+	awardedAt := make(map[string]model.UserAchievement, len(awarded))
+	for _, achievement := range awarded {
+		awardedAt[achievement.AchievementCode] = achievement
+	}
+
+	progress := make([]model.AchievementProgress, len(achievementRules))
+	for i, rule := range achievementRules {
+		progress[i] = model.AchievementProgress{
+			Rule:    rule,
+			Current: min(rule.Progress(totals), rule.Target),
+		}
+		if achievement, ok := awardedAt[rule.Code]; ok {
+			progress[i].AwardedAt = &achievement.AwardedAt
+		}
+	}
+
+	return progress, nil
+}
+
+// BackfillAchievements evaluates the rules for every user with activities, so
+// new rules reach users who will not log another activity for a while.
+func (c *AchievementUseCase) BackfillAchievements(ctx context.Context, log *logrus.Logger) (int, error) {
+	userIds, err := c.activityRepo.ListActiveUserIds(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list users")
+	}
+
+	total := 0
+	for _, userId := range userIds {
+		awarded, err := c.EvaluateAchievements(ctx, userId)
+		if err != nil {
+			return total, errors.Wrapf(err, "failed to evaluate achievements of user %d", userId)
+		}
+		if len(awarded) > 0 {
+			log.Infof("awarded %d achievements to user %d", len(awarded), userId)
+		}
+		total += len(awarded)
+	}
+
+	return total, nil
+}
@@ -2,23 +2,113 @@ package dto
 
 import (
 	"fit-byte/internal/activity/model"
+	recordModel "fit-byte/internal/records/model"
+	"fit-byte/pkg/helper"
 	"time"
 )
 
 type ActivityResponse struct {
-	ActivityId        string                 `json:"activityId"`
-	ActivityType      model.ActivityTypeEnum `json:"activityType"`
-	DoneAt            string                 `json:"doneAt"`
-	DurationInMinutes int                    `json:"durationInMinutes"`
-	CaloriesBurned    int                    `json:"caloriesBurned"`
-	CreatedAt         string                 `json:"createdAt"`
-	UpdatedAt         string                 `json:"updatedAt"`
+	ActivityId          string                 `json:"activityId"`
+	ActivityType        model.ActivityTypeEnum `json:"activityType"`
+	DoneAt              string                 `json:"doneAt"`
+	DurationInMinutes   int                    `json:"durationInMinutes"`
+	CaloriesBurned      int                    `json:"caloriesBurned"`
+	DistanceMeters      *float64               `json:"distanceMeters"`
+	ElevationGainMeters *float64               `json:"elevationGainMeters"`
+	AvgHeartRate        *int                   `json:"avgHeartRate"`
+	MaxHeartRate        *int                   `json:"maxHeartRate"`
+	Notes               *string                `json:"notes"`
+	PaceSecondsPerKm    *float64               `json:"paceSecondsPerKm"`
+	SpeedKmPerHour      *float64               `json:"speedKmPerHour"`
+	Exercises           []ExerciseResponse     `json:"exercises,omitempty"`
+	DeletedAt           *string                `json:"deletedAt,omitempty"`
+	CreatedAt           string                 `json:"createdAt"`
+	UpdatedAt           string                 `json:"updatedAt"`
+}
+
+type ExerciseSetResponse struct {
+	Reps   int     `json:"reps"`
+	Weight float64 `json:"weight"`
+}
+
+// ExerciseResponse reports weights, volume and the Epley one-rep-max in the
+// weight unit the user had when the activity was logged.
+type ExerciseResponse struct {
+	Name               string                `json:"name"`
+	WeightUnit         model.WeightUnitEnum  `json:"weightUnit"`
+	Sets               []ExerciseSetResponse `json:"sets"`
+	TotalVolume        float64               `json:"totalVolume"`
+	EstimatedOneRepMax float64               `json:"estimatedOneRepMax"`
+}
+
+type ActivityTypeResponse struct {
+	ActivityTypeId    string                     `json:"activityTypeId"`
+	Name              model.ActivityTypeEnum     `json:"name"`
+	Category          model.ActivityCategoryEnum `json:"category"`
+	CaloriesPerMinute *int                       `json:"caloriesPerMinute"`
+	MetValue          *float64                   `json:"metValue"`
+	Custom            bool                       `json:"custom"`
+}
+
+// CreateActivityTypeRequest adds a custom type. At least one of
+// caloriesPerMinute and metValue is required.
+type CreateActivityTypeRequest struct {
+	Name              string                     `json:"name" validate:"required,min=2,max=50"`
+	Category          model.ActivityCategoryEnum `json:"category" validate:"required,oneof=CARDIO STRENGTH FLEXIBILITY"`
+	CaloriesPerMinute *int                       `json:"caloriesPerMinute" validate:"required_without=MetValue,omitempty,min=1,max=100"`
+	MetValue          *float64                   `json:"metValue" validate:"required_without=CaloriesPerMinute,omitempty,gt=0,max=25"`
+}
+
+// NewRecordResponse is a personal record set by the activity just saved.
+type NewRecordResponse struct {
+	ActivityType model.ActivityTypeEnum     `json:"activityType"`
+	RecordType   recordModel.RecordTypeEnum `json:"recordType"`
+	Value        int                        `json:"value"`
+	Unit         string                     `json:"unit"`
+	PeriodStart  *string                    `json:"periodStart"`
+}
+
+type ActivityWithRecordsResponse struct {
+	ActivityResponse
+	NewRecords []NewRecordResponse `json:"newRecords"`
+}
+
+// ExerciseSetRequest weights are in the user's weight unit.
+type ExerciseSetRequest struct {
+	Reps   int     `json:"reps" validate:"required,min=1,max=1000"`
+	Weight float64 `json:"weight" validate:"min=0,max=2000"`
+}
+
+type ExerciseRequest struct {
+	Name string               `json:"name" validate:"required,min=1,max=100"`
+	Sets []ExerciseSetRequest `json:"sets" validate:"required,min=1,max=50,dive"`
 }
 
 type CreateAndUpdateActivityRequest struct {
-	ActivityType      model.ActivityTypeEnum `json:"activityType" validate:"required,activity_type"`
-	DoneAt            time.Time              `json:"doneAt" validate:"required,time_validator"`
-	DurationInMinutes int                    `json:"durationInMinutes" validate:"required,min=1"`
+	ActivityType        model.ActivityTypeEnum `json:"activityType" validate:"required,activity_type"`
+	DoneAt              time.Time              `json:"doneAt" validate:"required,time_validator"`
+	DurationInMinutes   int                    `json:"durationInMinutes" validate:"required,min=1"`
+	DistanceMeters      *float64               `json:"distanceMeters" validate:"omitempty,gt=0,max=1000000"`
+	AvgHeartRate        *int                   `json:"avgHeartRate" validate:"omitempty,min=30,max=250"`
+	MaxHeartRate        *int                   `json:"maxHeartRate" validate:"omitempty,min=30,max=250"`
+	ElevationGainMeters *float64               `json:"elevationGainMeters" validate:"omitempty,min=0,max=100000"`
+	Notes               *string                `json:"notes" validate:"omitempty,max=500"`
+	Exercises           []ExerciseRequest      `json:"exercises" validate:"omitempty,max=30,dive"`
+}
+
+// PatchActivityRequest only changes the fields present in the body. Sending
+// null clears an optional field. Exercises replace the existing ones, an
+// empty list removes them.
+type PatchActivityRequest struct {
+	ActivityType        helper.Nullable[model.ActivityTypeEnum] `json:"activityType" validate:"omitempty,activity_type"`
+	DoneAt              helper.Nullable[time.Time]              `json:"doneAt" validate:"omitempty,time_validator"`
+	DurationInMinutes   helper.Nullable[int]                    `json:"durationInMinutes" validate:"omitempty,min=1"`
+	DistanceMeters      helper.Nullable[float64]                `json:"distanceMeters" validate:"omitempty,gt=0,max=1000000"`
+	AvgHeartRate        helper.Nullable[int]                    `json:"avgHeartRate" validate:"omitempty,min=30,max=250"`
+	MaxHeartRate        helper.Nullable[int]                    `json:"maxHeartRate" validate:"omitempty,min=30,max=250"`
+	ElevationGainMeters helper.Nullable[float64]                `json:"elevationGainMeters" validate:"omitempty,min=0,max=100000"`
+	Notes               helper.Nullable[string]                 `json:"notes" validate:"omitempty,max=500"`
+	Exercises           *[]ExerciseRequest                      `json:"exercises" validate:"omitempty,max=30,dive"`
 }
 
 type GetActivityRequest struct {
@@ -29,4 +119,86 @@ type GetActivityRequest struct {
 	DoneAtTo          *time.Time              `query:"doneAtTo" validate:"omitempty,time_validator"`
 	CaloriesBurnedMin *int                    `query:"caloriesBurnedMin" validate:"omitempty,min=0"`
 	CaloriesBurnedMax *int                    `query:"caloriesBurnedMax" validate:"omitempty,min=0"`
+	DistanceMetersMin *float64                `query:"distanceMetersMin" validate:"omitempty,min=0"`
+	DistanceMetersMax *float64                `query:"distanceMetersMax" validate:"omitempty,min=0"`
+	AvgHeartRateMin   *int                    `query:"avgHeartRateMin" validate:"omitempty,min=0"`
+	AvgHeartRateMax   *int                    `query:"avgHeartRateMax" validate:"omitempty,min=0"`
+	Cursor            *string                 `query:"cursor"`
+	Sort              string                  `query:"sort" validate:"omitempty,activity_sort"`
+}
+
+type GetTrashRequest struct {
+	Limit  int `query:"limit" validate:"omitempty,min=0"`
+	Offset int `query:"offset" validate:"omitempty,min=0"`
+}
+
+type ActivityListResponse struct {
+	Data       []ActivityResponse `json:"data"`
+	NextCursor *string            `json:"nextCursor"`
+}
+
+type GetActivityStatsRequest struct {
+	Period       string                  `query:"period" validate:"omitempty,oneof=day week month"`
+	GroupByType  bool                    `query:"groupByType"`
+	ActivityType *model.ActivityTypeEnum `query:"activityType" validate:"omitempty,activity_type"`
+	DoneAtFrom   *time.Time              `query:"doneAtFrom" validate:"omitempty,time_validator"`
+	DoneAtTo     *time.Time              `query:"doneAtTo" validate:"omitempty,time_validator"`
+}
+
+type ActivityStatResponse struct {
+	PeriodStart            string                  `json:"periodStart"`
+	ActivityType           *model.ActivityTypeEnum `json:"activityType,omitempty"`
+	TotalDurationInMinutes int                     `json:"totalDurationInMinutes"`
+	TotalCaloriesBurned    int                     `json:"totalCaloriesBurned"`
+	SessionCount           int                     `json:"sessionCount"`
+}
+
+type ActivityStatsResponse struct {
+	Period string                 `json:"period"`
+	Stats  []ActivityStatResponse `json:"stats"`
+}
+
+type ActivityStreakResponse struct {
+	Current    int     `json:"current"`
+	Longest    int     `json:"longest"`
+	LastActive *string `json:"lastActive"`
+}
+
+type ActivityStreaksResponse struct {
+	TimeZone string                 `json:"timeZone"`
+	Daily    ActivityStreakResponse `json:"daily"`
+	Weekly   ActivityStreakResponse `json:"weekly"`
+}
+
+type ImportActivitiesRequest struct {
+	DryRun bool `query:"dryRun"`
+}
+
+type ImportLineError struct {
+	Line    int    `json:"line"`
+	Message string `json:"message"`
+}
+
+type ImportActivitiesResponse struct {
+	DryRun       bool              `json:"dryRun"`
+	TotalRows    int               `json:"totalRows"`
+	ImportedRows int               `json:"importedRows"`
+	Errors       []ImportLineError `json:"errors"`
+}
+
+type UploadActivityFileRequest struct {
+	ActivityType *model.ActivityTypeEnum `form:"activityType" validate:"omitempty,activity_type"`
+}
+
+type ExportActivityRequest struct {
+	Format            string                  `query:"format" validate:"required,oneof=csv jsonl"`
+	ActivityType      *model.ActivityTypeEnum `query:"activityType" validate:"omitempty,activity_type"`
+	DoneAtFrom        *time.Time              `query:"doneAtFrom" validate:"omitempty,time_validator"`
+	DoneAtTo          *time.Time              `query:"doneAtTo" validate:"omitempty,time_validator"`
+	CaloriesBurnedMin *int                    `query:"caloriesBurnedMin" validate:"omitempty,min=0"`
+	CaloriesBurnedMax *int                    `query:"caloriesBurnedMax" validate:"omitempty,min=0"`
+	DistanceMetersMin *float64                `query:"distanceMetersMin" validate:"omitempty,min=0"`
+	DistanceMetersMax *float64                `query:"distanceMetersMax" validate:"omitempty,min=0"`
+	AvgHeartRateMin   *int                    `query:"avgHeartRateMin" validate:"omitempty,min=0"`
+	AvgHeartRateMax   *int                    `query:"avgHeartRateMax" validate:"omitempty,min=0"`
 }
@@ -0,0 +1,137 @@
+package handler
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model"
+	"fit-byte/internal/activity/model/converter"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	EXPORT_FORMAT_CSV   = "csv"
+	EXPORT_FORMAT_JSONL = "jsonl"
+	EXPORT_FLUSH_EVERY  = 100
+)
+
+var exportColumns = []string{
+	"activityId",
+	"activityType",
+	"doneAt",
+	"durationInMinutes",
+	"caloriesBurned",
+	"distanceMeters",
+	"elevationGainMeters",
+	"createdAt",
+	"updatedAt",
+}
+
+func (c *ActivityHandler) ExportActivities(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.ExportActivityRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	res := ctx.Response()
+	write, flush, finish := newExportWriter(res, request.Format)
+
+	res.Header().Set(echo.HeaderContentDisposition, "attachment; filename=\"activities."+request.Format+"\"")
+	res.WriteHeader(http.StatusOK)
+
+	// the status is already sent, so a failure mid-stream can only cut the body short
+	written := 0
+	err := c.UseCase.ExportActivities(ctx.Request().Context(), request, userData.ID, func(activity model.Activity) error {
+		if err := write(converter.ToActivityResponse(activity)); err != nil {
+			return err
+		}
+		written++
+		if written%EXPORT_FLUSH_EVERY == 0 {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	return finish()
+}
+
+// newExportWriter returns functions to write one activity, flush buffered rows
+// to the client and finish the stream.
+func newExportWriter(res *echo.Response, format string) (func(dto.ActivityResponse) error, func() error, func() error) {
+	if format == EXPORT_FORMAT_JSONL {
+		res.Header().Set(echo.HeaderContentType, "application/x-ndjson")
+		encoder := json.NewEncoder(res)
+		flush := func() error {
+			res.Flush()
+			return nil
+		}
+		write := func(activity dto.ActivityResponse) error {
+			return encoder.Encode(activity)
+		}
+		return write, flush, flush
+	}
+
+	res.Header().Set(echo.HeaderContentType, "text/csv")
+	writer := csv.NewWriter(res)
+	headerWritten := false
+	write := func(activity dto.ActivityResponse) error {
+		if !headerWritten {
+			if err := writer.Write(exportColumns); err != nil {
+				return err
+			}
+			headerWritten = true
+		}
+		return writer.Write([]string{
+			activity.ActivityId,
+			string(activity.ActivityType),
+			activity.DoneAt,
+			strconv.Itoa(activity.DurationInMinutes),
+			strconv.Itoa(activity.CaloriesBurned),
+			formatOptionalFloat(activity.DistanceMeters),
+			formatOptionalFloat(activity.ElevationGainMeters),
+			activity.CreatedAt,
+			activity.UpdatedAt,
+		})
+	}
+	flush := func() error {
+		writer.Flush()
+		res.Flush()
+		return writer.Error()
+	}
+	finish := func() error {
+		if !headerWritten {
+			if err := writer.Write(exportColumns); err != nil {
+				return err
+			}
+		}
+		return flush()
+	}
+	return write, flush, finish
+}
+
+func formatOptionalFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
@@ -1,13 +1,16 @@
 package handler
 
 import (
+	"encoding/json"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"fit-byte/internal/activity/dto"
 	"fit-byte/internal/activity/model/converter"
 	"fit-byte/internal/activity/usecase"
 	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/helper"
 	"fit-byte/pkg/jwt"
 	"fit-byte/pkg/response"
 
@@ -17,7 +20,11 @@ import (
 )
 
 const (
-	DEFAULT_LIMIT = 5
+	DEFAULT_LIMIT        = 5
+	DEFAULT_STATS_PERIOD = "day"
+	CURSOR_SORT          = "-doneAt"
+
+	MIME_MERGE_PATCH_JSON = "application/merge-patch+json"
 )
 
 type ActivityHandler struct {
@@ -51,6 +58,11 @@ func (c *ActivityHandler) GetActivity(ctx echo.Context) error {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
+	// clients opt into keyset pagination by sending a cursor, even an empty one
+	if request.Cursor != nil {
+		return c.getActivityPage(ctx, request, userData.ID)
+	}
+
 	activities, err := c.UseCase.GetActivity(ctx.Request().Context(), request, userData.ID)
 	if err != nil {
 		return ctx.JSON(response.WriteErrorResponse(err))
@@ -61,6 +73,69 @@ func (c *ActivityHandler) GetActivity(ctx echo.Context) error {
 	return ctx.JSON(http.StatusOK, response)
 }
 
+func (c *ActivityHandler) getActivityPage(ctx echo.Context, request *dto.GetActivityRequest, userId int) error {
+	if request.Offset != 0 {
+		err := errors.Wrap(customErrors.ErrBadRequest, "offset cannot be combined with cursor")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if request.Sort != "" && request.Sort != CURSOR_SORT {
+		err := errors.Wrap(customErrors.ErrBadRequest, "cursor only supports sort="+CURSOR_SORT)
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	activities, nextCursor, err := c.UseCase.GetActivityPage(ctx.Request().Context(), request, userId)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityListResponse(activities, nextCursor)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ActivityHandler) GetActivityStats(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.GetActivityStatsRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if request.Period == "" {
+		request.Period = DEFAULT_STATS_PERIOD
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	stats, err := c.UseCase.GetActivityStats(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityStatsResponse(request.Period, stats)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ActivityHandler) GetActivityStreaks(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	streaks, err := c.UseCase.GetActivityStreaks(ctx.Request().Context(), userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityStreaksResponse(*streaks)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
 func (c *ActivityHandler) CreateActivity(ctx echo.Context) error {
 	userData := ctx.Get("user").(*jwt.JWTClaim)
 
@@ -76,19 +151,40 @@ func (c *ActivityHandler) CreateActivity(ctx echo.Context) error {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
-	activity, err := c.UseCase.CreateActivity(ctx.Request().Context(), request, userData.ID)
+	activity, records, err := c.UseCase.CreateActivity(ctx.Request().Context(), request, userData.ID)
 	if err != nil {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
-	response := converter.ToActivityResponse(*activity)
+	response := converter.ToActivityWithRecordsResponse(*activity, records)
 
 	return ctx.JSON(http.StatusCreated, response)
 }
 
+func (c *ActivityHandler) GetActivityById(ctx echo.Context) error {
+	activityId := ctx.Param("activityId")
+
+	intValue, err := strconv.Atoi(activityId)
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "activity id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	activity, err := c.UseCase.GetActivityById(ctx.Request().Context(), intValue, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityResponse(*activity)
+	ctx.Response().Header().Set(helper.HeaderETag, helper.ETag(activity.UpdatedAt))
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
 func (c *ActivityHandler) UpdateActivity(ctx echo.Context) error {
 
-	var request = new(dto.CreateAndUpdateActivityRequest)
+	var request = new(dto.PatchActivityRequest)
 	activityId := ctx.Param("activityId")
 
 	intValue, err := strconv.Atoi(activityId)
@@ -102,7 +198,7 @@ func (c *ActivityHandler) UpdateActivity(ctx echo.Context) error {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
-	if err := ctx.Bind(request); err != nil {
+	if err := bindPatch(ctx, request); err != nil {
 		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
@@ -114,17 +210,30 @@ func (c *ActivityHandler) UpdateActivity(ctx echo.Context) error {
 
 	userData := ctx.Get("user").(*jwt.JWTClaim)
 
-	activity, err := c.UseCase.UpdateActivity(ctx.Request().Context(), request, intValue, userData.ID)
+	ifMatch := ctx.Request().Header.Get(helper.HeaderIfMatch)
+	activity, records, err := c.UseCase.UpdateActivity(ctx.Request().Context(), request, intValue, userData.ID, ifMatch)
 
 	if err != nil {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
-	response := converter.ToActivityResponse(*activity)
+	response := converter.ToActivityWithRecordsResponse(*activity, records)
+	ctx.Response().Header().Set(helper.HeaderETag, helper.ETag(activity.UpdatedAt))
 
 	return ctx.JSON(http.StatusOK, response)
 }
 
+// bindPatch decodes application/merge-patch+json bodies, which echo's default
+// binder rejects, and falls back to Bind for everything else.
+func bindPatch(ctx echo.Context, request *dto.PatchActivityRequest) error {
+	contentType := ctx.Request().Header.Get(echo.HeaderContentType)
+	if !strings.HasPrefix(contentType, MIME_MERGE_PATCH_JSON) {
+		return ctx.Bind(request)
+	}
+
+	return json.NewDecoder(ctx.Request().Body).Decode(request)
+}
+
 func (c *ActivityHandler) DeleteActivity(ctx echo.Context) error {
 	activityId := ctx.Param("activityId")
 
@@ -148,3 +257,52 @@ func (c *ActivityHandler) DeleteActivity(ctx echo.Context) error {
 		Message: "deleted",
 	})
 }
+
+func (c *ActivityHandler) GetTrash(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.GetTrashRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if request.Limit == 0 {
+		request.Limit = DEFAULT_LIMIT
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	activities, err := c.UseCase.GetTrash(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityResponseList(activities)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ActivityHandler) RestoreActivity(ctx echo.Context) error {
+	activityId := ctx.Param("activityId")
+
+	intValue, err := strconv.Atoi(activityId)
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "activity id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	activity, err := c.UseCase.RestoreActivity(ctx.Request().Context(), intValue, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityResponse(*activity)
+
+	return ctx.JSON(http.StatusOK, response)
+}
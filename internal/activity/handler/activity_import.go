@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	MAX_IMPORT_FILE_SIZE = 1024 * 1024
+	MAX_IMPORT_ROWS      = 1000
+	// room for the multipart headers and boundaries around the file
+	MAX_MULTIPART_OVERHEAD = 64 * 1024
+)
+
+var importColumns = []string{"activityType", "doneAt", "durationInMinutes"}
+
+// limitMultipartBody bounds the request body before it is parsed. Parsing
+// reads the whole multipart body, spilling large files to disk, before the
+// size of the file can be checked.
+func limitMultipartBody(ctx echo.Context, maxFileSize int64) {
+	req := ctx.Request()
+	req.Body = http.MaxBytesReader(ctx.Response(), req.Body, maxFileSize+MAX_MULTIPART_OVERHEAD)
+}
+
+// multipartError turns an error from parsing a limited multipart body into a
+// bad request.
+func multipartError(err error) error {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return errors.Wrap(customErrors.ErrBadRequest, "file is too large")
+	}
+	return errors.Wrap(customErrors.ErrBadRequest, err.Error())
+}
+
+func (c *ActivityHandler) ImportActivities(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.ImportActivitiesRequest)
+
+	// Bind only reads query params for GET/DELETE, so bind them explicitly
+	if err := (&echo.DefaultBinder{}).BindQueryParams(ctx, request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	limitMultipartBody(ctx, MAX_IMPORT_FILE_SIZE)
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(multipartError(err)))
+	}
+
+	if fileHeader.Size > MAX_IMPORT_FILE_SIZE {
+		err = errors.Wrap(customErrors.ErrBadRequest, "file is too large")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	defer file.Close()
+
+	activities, lineErrors, err := c.parseImportFile(file)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	result := dto.ImportActivitiesResponse{
+		DryRun:    request.DryRun,
+		TotalRows: len(activities) + len(lineErrors),
+		Errors:    lineErrors,
+	}
+
+	// the import is all or nothing, so any invalid line rejects the file
+	if len(lineErrors) > 0 {
+		return ctx.JSON(http.StatusBadRequest, result)
+	}
+
+	if request.DryRun {
+		return ctx.JSON(http.StatusOK, result)
+	}
+
+	if err := c.UseCase.ImportActivities(ctx.Request().Context(), activities, userData.ID); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	result.ImportedRows = len(activities)
+
+	return ctx.JSON(http.StatusCreated, result)
+}
+
+// parseImportFile reads a CSV with an activityType, doneAt and durationInMinutes
+// header and validates every line with the same rules as CreateActivity.
+func (c *ActivityHandler) parseImportFile(file io.Reader) ([]dto.CreateAndUpdateActivityRequest, []dto.ImportLineError, error) {
+	reader := csv.NewReader(file)
+	reader.FieldsPerRecord = len(importColumns)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "missing csv header")
+	}
+
+	positions := map[string]int{}
+	for i, column := range header {
+		positions[strings.TrimSpace(column)] = i
+	}
+	for _, column := range importColumns {
+		if _, ok := positions[column]; !ok {
+			return nil, nil, errors.Wrap(customErrors.ErrBadRequest, fmt.Sprintf("missing csv column %s", column))
+		}
+	}
+
+	activities := []dto.CreateAndUpdateActivityRequest{}
+	lineErrors := []dto.ImportLineError{}
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+
+		if len(activities)+len(lineErrors) >= MAX_IMPORT_ROWS {
+			return nil, nil, errors.Wrap(customErrors.ErrBadRequest, fmt.Sprintf("file exceeds %d rows", MAX_IMPORT_ROWS))
+		}
+
+		if err != nil {
+			var parseErr *csv.ParseError
+			if !errors.As(err, &parseErr) {
+				return nil, nil, errors.Wrap(customErrors.ErrBadRequest, err.Error())
+			}
+			lineErrors = append(lineErrors, dto.ImportLineError{Line: parseErr.Line, Message: parseErr.Err.Error()})
+			continue
+		}
+
+		line, _ := reader.FieldPos(0)
+
+		activity, err := c.parseImportRecord(record, positions)
+		if err != nil {
+			lineErrors = append(lineErrors, dto.ImportLineError{Line: line, Message: err.Error()})
+			continue
+		}
+		activities = append(activities, *activity)
+	}
+
+	return activities, lineErrors, nil
+}
+
+func (c *ActivityHandler) parseImportRecord(record []string, positions map[string]int) (*dto.CreateAndUpdateActivityRequest, error) {
+	doneAt, err := time.Parse(time.RFC3339, strings.TrimSpace(record[positions["doneAt"]]))
+	if err != nil {
+		return nil, errors.New("doneAt must be an RFC3339 timestamp")
+	}
+
+	duration, err := strconv.Atoi(strings.TrimSpace(record[positions["durationInMinutes"]]))
+	if err != nil {
+		return nil, errors.New("durationInMinutes must be a number")
+	}
+
+	activity := dto.CreateAndUpdateActivityRequest{
+		ActivityType:      model.ActivityTypeEnum(strings.TrimSpace(record[positions["activityType"]])),
+		DoneAt:            doneAt,
+		DurationInMinutes: duration,
+	}
+
+	if err := c.Validate.Struct(activity); err != nil {
+		return nil, err
+	}
+
+	return &activity, nil
+}
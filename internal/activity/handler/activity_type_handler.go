@@ -0,0 +1,87 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model/converter"
+	"fit-byte/internal/activity/usecase"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+// ActivityTypeHandler shares the activity type cache with the validator, so it
+// keeps a pointer instead of a copy.
+type ActivityTypeHandler struct {
+	UseCase  *usecase.ActivityTypeUseCase
+	Validate *validator.Validate
+}
+
+func NewActivityTypeHandler(useCase *usecase.ActivityTypeUseCase, validate *validator.Validate) *ActivityTypeHandler {
+	return &ActivityTypeHandler{
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func (c *ActivityTypeHandler) GetActivityTypes(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	activityTypes, err := c.UseCase.GetActivityTypes(ctx.Request().Context(), userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityTypeResponseList(activityTypes)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *ActivityTypeHandler) CreateActivityType(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.CreateActivityTypeRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	activityType, err := c.UseCase.CreateActivityType(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityTypeResponse(*activityType)
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (c *ActivityTypeHandler) DeleteActivityType(ctx echo.Context) error {
+	activityTypeId, err := strconv.Atoi(ctx.Param("activityTypeId"))
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "activity type id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	err = c.UseCase.DeleteActivityType(ctx.Request().Context(), activityTypeId, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "deleted",
+	})
+}
@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"net/http"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model/converter"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+	"fit-byte/pkg/workout"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+const (
+	MAX_WORKOUT_FILE_SIZE = 10 * 1024 * 1024
+)
+
+func (c *ActivityHandler) UploadActivityFile(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.UploadActivityFileRequest)
+
+	// Bind already parses the multipart body
+	limitMultipartBody(ctx, MAX_WORKOUT_FILE_SIZE)
+	if err := ctx.Bind(request); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(multipartError(err)))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	fileHeader, err := ctx.FormFile("file")
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(multipartError(err)))
+	}
+
+	format, ok := workout.FormatFromFilename(fileHeader.Filename)
+	if !ok || fileHeader.Size > MAX_WORKOUT_FILE_SIZE {
+		err = errors.Wrap(customErrors.ErrBadRequest, "file is invalid")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	file, err := fileHeader.Open()
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	defer file.Close()
+
+	parsed, err := workout.Parse(file, format)
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	activity, records, err := c.UseCase.CreateActivityFromWorkout(ctx.Request().Context(), parsed, request.ActivityType, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToActivityWithRecordsResponse(*activity, records)
+
+	return ctx.JSON(http.StatusCreated, response)
+}
@@ -0,0 +1,60 @@
+package model
+
+import "fmt"
+
+type WeightUnitEnum string
+
+const (
+	WeightUnitEnumKg  WeightUnitEnum = "KG"
+	WeightUnitEnumLbs WeightUnitEnum = "LBS"
+)
+
+func (e *WeightUnitEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = WeightUnitEnum(s)
+	case string:
+		*e = WeightUnitEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for WeightUnitEnum: %T", src)
+	}
+	return nil
+}
+
+type ExerciseSet struct {
+	Reps   int
+	Weight float64
+}
+
+// Exercise is one movement of a strength activity. The weights of its sets are
+// in WeightUnit.
+type Exercise struct {
+	ID         int
+	ActivityId int
+	Name       string
+	WeightUnit WeightUnitEnum
+	Sets       []ExerciseSet
+}
+
+// TotalVolume is the sum of reps times weight over all sets.
+func (e Exercise) TotalVolume() float64 {
+	var volume float64
+	for _, set := range e.Sets {
+		volume += float64(set.Reps) * set.Weight
+	}
+	return volume
+}
+
+// EstimatedOneRepMax is the best one-rep-max of all sets using the Epley
+// formula weight * (1 + reps / 30). A single rep is taken as is.
+func (e Exercise) EstimatedOneRepMax() float64 {
+	var best float64
+	for _, set := range e.Sets {
+		oneRepMax := set.Weight
+		if set.Reps > 1 {
+			oneRepMax = set.Weight * (1 + float64(set.Reps)/30)
+		}
+		best = max(best, oneRepMax)
+	}
+	return best
+}
@@ -2,9 +2,12 @@ package model
 
 import (
 	"fmt"
+	"strings"
 	"time"
 )
 
+// ActivityTypeEnum is the name of an activity type. The constants are the
+// global types seeded into activity_types, users may add their own.
 type ActivityTypeEnum string
 
 const (
@@ -18,6 +21,8 @@ const (
 	ActivityTypeEnumRunning    ActivityTypeEnum = "Running"
 	ActivityTypeEnumHIIT       ActivityTypeEnum = "HIIT"
 	ActivityTypeEnumJumpRope   ActivityTypeEnum = "JumpRope"
+
+	ActivityTypeEnumWeightTraining ActivityTypeEnum = "WeightTraining"
 )
 
 func (e *ActivityTypeEnum) Scan(src interface{}) error {
@@ -33,12 +38,107 @@ func (e *ActivityTypeEnum) Scan(src interface{}) error {
 }
 
 type Activity struct {
-	ID                int
-	UserId            int
-	ActivityType      ActivityTypeEnum
-	DoneAt            time.Time
-	DurationInMinutes int
-	CaloriesBurned    int
-	CreatedAt         time.Time
-	UpdatedAt         time.Time
+	ID                  int
+	UserId              int
+	ActivityType        ActivityTypeEnum
+	DoneAt              time.Time
+	DurationInMinutes   int
+	CaloriesBurned      int
+	CalorieModel        string
+	MetValue            *float64
+	WeightKg            *float64
+	DistanceMeters      *float64
+	ElevationGainMeters *float64
+	AvgHeartRate        *int
+	MaxHeartRate        *int
+	Notes               *string
+	Exercises           []Exercise
+	DeletedAt           *time.Time
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+type UserWeight struct {
+	Weight     *int
+	WeightUnit *string
+}
+
+type ActivityStat struct {
+	PeriodStart            time.Time
+	ActivityType           *ActivityTypeEnum
+	TotalDurationInMinutes int
+	TotalCaloriesBurned    int
+	SessionCount           int
+}
+
+// ActivityTotal is the all time total of one activity type.
+type ActivityTotal struct {
+	ActivityType           ActivityTypeEnum
+	TotalDurationInMinutes int
+	TotalCaloriesBurned    int
+	SessionCount           int
+}
+
+// ActivityStreak is the run of consecutive days or weeks with at least one
+// activity, counted in the user's time zone.
+type ActivityStreak struct {
+	Current    int
+	Longest    int
+	LastActive *time.Time
+}
+
+type ActivityStreaks struct {
+	TimeZone string
+	Daily    ActivityStreak
+	Weekly   ActivityStreak
+}
+
+// DistanceBasedActivityTypes are the activity types for which pace and speed
+// are meaningful.
+var DistanceBasedActivityTypes = map[ActivityTypeEnum]struct{}{
+	ActivityTypeEnumWalking:  {},
+	ActivityTypeEnumCycling:  {},
+	ActivityTypeEnumSwimming: {},
+	ActivityTypeEnumHiking:   {},
+	ActivityTypeEnumRunning:  {},
+}
+
+// ActivitySortColumns whitelists the keys accepted by the sort query parameter.
+var ActivitySortColumns = map[string]string{
+	"doneAt":            "done_at",
+	"caloriesBurned":    "calories_burned",
+	"durationInMinutes": "duration_in_minutes",
+	"createdAt":         "created_at",
+}
+
+type ActivitySort struct {
+	Column string
+	Desc   bool
+}
+
+// ParseActivitySort parses a comma separated list such as "-doneAt,caloriesBurned".
+// A leading "-" sorts that key in descending order.
+func ParseActivitySort(sort string) ([]ActivitySort, bool) {
+	var sorts []ActivitySort
+	seen := map[string]struct{}{}
+	for _, key := range strings.Split(sort, ",") {
+		key = strings.TrimSpace(key)
+		desc := strings.HasPrefix(key, "-")
+		key = strings.TrimPrefix(key, "-")
+
+		column, ok := ActivitySortColumns[key]
+		if !ok {
+			return nil, false
+		}
+		if _, duplicate := seen[column]; duplicate {
+			return nil, false
+		}
+		seen[column] = struct{}{}
+
+		sorts = append(sorts, ActivitySort{
+			Column: column,
+			Desc:   desc,
+		})
+	}
+	return sorts, true
 }
@@ -0,0 +1,43 @@
+package model
+
+import (
+	"fmt"
+	"time"
+)
+
+type ActivityCategoryEnum string
+
+const (
+	ActivityCategoryEnumCardio      ActivityCategoryEnum = "CARDIO"
+	ActivityCategoryEnumStrength    ActivityCategoryEnum = "STRENGTH"
+	ActivityCategoryEnumFlexibility ActivityCategoryEnum = "FLEXIBILITY"
+)
+
+func (e *ActivityCategoryEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = ActivityCategoryEnum(s)
+	case string:
+		*e = ActivityCategoryEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for ActivityCategoryEnum: %T", src)
+	}
+	return nil
+}
+
+// ActivityType is a global type when UserId is nil and a user's custom type
+// otherwise. At least one of CaloriesPerMinute and MetValue is set.
+type ActivityType struct {
+	ID                int
+	UserId            *int
+	Name              ActivityTypeEnum
+	Category          ActivityCategoryEnum
+	CaloriesPerMinute *int
+	MetValue          *float64
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+func (t ActivityType) IsCustom() bool {
+	return t.UserId != nil
+}
@@ -3,20 +3,113 @@ package converter
 import (
 	"fit-byte/internal/activity/dto"
 	"fit-byte/internal/activity/model"
+	recordModel "fit-byte/internal/records/model"
 	"fit-byte/pkg/helper"
+	"math"
 	"strconv"
+	"time"
 )
 
 func ToActivityResponse(activity model.Activity) dto.ActivityResponse {
+	paceSecondsPerKm, speedKmPerHour := pace(activity)
+
+	var deletedAt *string
+	if activity.DeletedAt != nil {
+		formatted := helper.FormatTimeToUTC(*activity.DeletedAt)
+		deletedAt = &formatted
+	}
+
 	return dto.ActivityResponse{
-		ActivityId:        strconv.Itoa(activity.ID),
-		ActivityType:      activity.ActivityType,
-		DoneAt:            helper.FormatTimeToUTC(activity.DoneAt),
-		DurationInMinutes: activity.DurationInMinutes,
-		CaloriesBurned:    activity.CaloriesBurned,
-		CreatedAt:         helper.FormatTimeToUTC(activity.CreatedAt),
-		UpdatedAt:         helper.FormatTimeToUTC(activity.UpdatedAt),
+		ActivityId:          strconv.Itoa(activity.ID),
+		ActivityType:        activity.ActivityType,
+		DoneAt:              helper.FormatTimeToUTC(activity.DoneAt),
+		DurationInMinutes:   activity.DurationInMinutes,
+		CaloriesBurned:      activity.CaloriesBurned,
+		DistanceMeters:      activity.DistanceMeters,
+		ElevationGainMeters: activity.ElevationGainMeters,
+		AvgHeartRate:        activity.AvgHeartRate,
+		MaxHeartRate:        activity.MaxHeartRate,
+		Notes:               activity.Notes,
+		PaceSecondsPerKm:    paceSecondsPerKm,
+		SpeedKmPerHour:      speedKmPerHour,
+		Exercises:           ToExerciseResponseList(activity.Exercises),
+		DeletedAt:           deletedAt,
+		CreatedAt:           helper.FormatTimeToUTC(activity.CreatedAt),
+		UpdatedAt:           helper.FormatTimeToUTC(activity.UpdatedAt),
+	}
+}
+
+func ToExerciseResponse(exercise model.Exercise) dto.ExerciseResponse {
+	sets := make([]dto.ExerciseSetResponse, len(exercise.Sets))
+	for i, set := range exercise.Sets {
+		sets[i] = dto.ExerciseSetResponse{
+			Reps:   set.Reps,
+			Weight: set.Weight,
+		}
+	}
+
+	return dto.ExerciseResponse{
+		Name:               exercise.Name,
+		WeightUnit:         exercise.WeightUnit,
+		Sets:               sets,
+		TotalVolume:        math.Round(exercise.TotalVolume()*100) / 100,
+		EstimatedOneRepMax: math.Round(exercise.EstimatedOneRepMax()*10) / 10,
+	}
+}
+
+// ToExerciseResponseList returns nil for activities without exercises, so the
+// field is left out of their response.
+func ToExerciseResponseList(exercises []model.Exercise) []dto.ExerciseResponse {
+	if len(exercises) == 0 {
+		return nil
+	}
+
+	responses := make([]dto.ExerciseResponse, len(exercises))
+	for i, exercise := range exercises {
+		responses[i] = ToExerciseResponse(exercise)
 	}
+	return responses
+}
+
+func ToActivityWithRecordsResponse(activity model.Activity, records []recordModel.PersonalRecord) dto.ActivityWithRecordsResponse {
+	newRecords := make([]dto.NewRecordResponse, len(records))
+	for i, record := range records {
+		var periodStart *string
+		if record.PeriodStart != nil {
+			formatted := record.PeriodStart.Format(time.DateOnly)
+			periodStart = &formatted
+		}
+		newRecords[i] = dto.NewRecordResponse{
+			ActivityType: record.ActivityType,
+			RecordType:   record.RecordType,
+			Value:        record.Value,
+			Unit:         record.RecordType.Unit(),
+			PeriodStart:  periodStart,
+		}
+	}
+
+	return dto.ActivityWithRecordsResponse{
+		ActivityResponse: ToActivityResponse(activity),
+		NewRecords:       newRecords,
+	}
+}
+
+// pace derives seconds per kilometer and kilometers per hour for distance
+// based activities.
+func pace(activity model.Activity) (*float64, *float64) {
+	if _, ok := model.DistanceBasedActivityTypes[activity.ActivityType]; !ok {
+		return nil, nil
+	}
+	if activity.DistanceMeters == nil || *activity.DistanceMeters <= 0 || activity.DurationInMinutes <= 0 {
+		return nil, nil
+	}
+
+	kilometers := *activity.DistanceMeters / 1000
+	seconds := float64(activity.DurationInMinutes * 60)
+
+	paceSecondsPerKm := math.Round(seconds/kilometers*100) / 100
+	speedKmPerHour := math.Round(kilometers/(seconds/3600)*100) / 100
+	return &paceSecondsPerKm, &speedKmPerHour
 }
 
 func ToActivityResponseList(activities []model.Activity) []dto.ActivityResponse {
@@ -31,3 +124,75 @@ func ToActivityResponseList(activities []model.Activity) []dto.ActivityResponse
 	}
 	return responses
 }
+
+func ToActivityListResponse(activities []model.Activity, nextCursor *string) dto.ActivityListResponse {
+	data := ToActivityResponseList(activities)
+	if data == nil {
+		data = []dto.ActivityResponse{}
+	}
+
+	return dto.ActivityListResponse{
+		Data:       data,
+		NextCursor: nextCursor,
+	}
+}
+
+func ToActivityStatsResponse(period string, stats []model.ActivityStat) dto.ActivityStatsResponse {
+	responses := []dto.ActivityStatResponse{}
+	for _, stat := range stats {
+		responses = append(responses, dto.ActivityStatResponse{
+			PeriodStart:            helper.FormatTimeToUTC(stat.PeriodStart),
+			ActivityType:           stat.ActivityType,
+			TotalDurationInMinutes: stat.TotalDurationInMinutes,
+			TotalCaloriesBurned:    stat.TotalCaloriesBurned,
+			SessionCount:           stat.SessionCount,
+		})
+	}
+
+	return dto.ActivityStatsResponse{
+		Period: period,
+		Stats:  responses,
+	}
+}
+
+func ToActivityStreakResponse(streak model.ActivityStreak) dto.ActivityStreakResponse {
+	// LastActive is a calendar date in the user's time zone, not an instant
+	var lastActive *string
+	if streak.LastActive != nil {
+		formatted := streak.LastActive.Format(time.DateOnly)
+		lastActive = &formatted
+	}
+
+	return dto.ActivityStreakResponse{
+		Current:    streak.Current,
+		Longest:    streak.Longest,
+		LastActive: lastActive,
+	}
+}
+
+func ToActivityStreaksResponse(streaks model.ActivityStreaks) dto.ActivityStreaksResponse {
+	return dto.ActivityStreaksResponse{
+		TimeZone: streaks.TimeZone,
+		Daily:    ToActivityStreakResponse(streaks.Daily),
+		Weekly:   ToActivityStreakResponse(streaks.Weekly),
+	}
+}
+
+func ToActivityTypeResponse(activityType model.ActivityType) dto.ActivityTypeResponse {
+	return dto.ActivityTypeResponse{
+		ActivityTypeId:    strconv.Itoa(activityType.ID),
+		Name:              activityType.Name,
+		Category:          activityType.Category,
+		CaloriesPerMinute: activityType.CaloriesPerMinute,
+		MetValue:          activityType.MetValue,
+		Custom:            activityType.IsCustom(),
+	}
+}
+
+func ToActivityTypeResponseList(activityTypes []model.ActivityType) []dto.ActivityTypeResponse {
+	responses := make([]dto.ActivityTypeResponse, 0, len(activityTypes))
+	for _, activityType := range activityTypes {
+		responses = append(responses, ToActivityTypeResponse(activityType))
+	}
+	return responses
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fit-byte/internal/activity/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/pkg/errors"
+)
+
+const createExercise = `-- name: CreateExercise :one
+INSERT INTO activity_exercises (
+  activity_id,
+  position,
+  name,
+  weight_unit
+) VALUES (
+  $1::bigint, $2::int, $3, $4
+) RETURNING id
+`
+
+const createExerciseSets = `-- name: CreateExerciseSets :exec
+INSERT INTO activity_exercise_sets (exercise_id, position, reps, weight)
+SELECT $1::bigint, s.position, s.reps, s.weight
+FROM unnest($2::int[], $3::numeric[]) WITH ORDINALITY AS s(reps, weight, position)
+`
+
+// createExercises stores the exercises of an activity in their given order and
+// returns them with their ids.
+func createExercises(ctx context.Context, tx pgx.Tx, activityId int, exercises []model.Exercise) ([]model.Exercise, error) {
+	created := make([]model.Exercise, 0, len(exercises))
+	for position, exercise := range exercises {
+		exercise.ActivityId = activityId
+		err := tx.QueryRow(ctx, createExercise, activityId, position+1, exercise.Name, exercise.WeightUnit).Scan(&exercise.ID)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to create exercise")
+		}
+
+		reps := make([]int, 0, len(exercise.Sets))
+		weights := make([]float64, 0, len(exercise.Sets))
+		for _, set := range exercise.Sets {
+			reps = append(reps, set.Reps)
+			weights = append(weights, set.Weight)
+		}
+		if _, err := tx.Exec(ctx, createExerciseSets, exercise.ID, reps, weights); err != nil {
+			return nil, errors.Wrap(err, "failed to create exercise sets")
+		}
+
+		created = append(created, exercise)
+	}
+	return created, nil
+}
+
+const deleteExercises = `-- name: DeleteExercises :exec
+DELETE FROM activity_exercises
+WHERE (activity_id = $1::bigint)
+`
+
+const listExercises = `-- name: ListExercises :many
+SELECT
+  e.id,
+  e.activity_id,
+  e.name,
+  e.weight_unit,
+  array_agg(s.reps ORDER BY s.position),
+  array_agg(s.weight::float8 ORDER BY s.position)
+FROM activity_exercises e
+JOIN activity_exercise_sets s ON s.exercise_id = e.id
+WHERE (e.activity_id = ANY($1::bigint[]))
+GROUP BY e.id
+ORDER BY e.activity_id, e.position
+`
+
+// AttachExercises loads the exercises of the given activities in a single
+// query and sets them in place.
+func (r *ActivityRepository) AttachExercises(ctx context.Context, activities []model.Activity) error {
+	if len(activities) == 0 {
+		return nil
+	}
+
+	ids := make([]int, 0, len(activities))
+	for _, activity := range activities {
+		ids = append(ids, activity.ID)
+	}
+
+	rows, err := r.pool.Query(ctx, listExercises, ids)
+	if err != nil {
+		return errors.Wrap(err, "failed to list exercises")
+	}
+	defer rows.Close()
+
+	exercises := map[int][]model.Exercise{}
+	for rows.Next() {
+		var (
+			i       model.Exercise
+			reps    []int
+			weights []float64
+		)
+		if err := rows.Scan(
+			&i.ID,
+			&i.ActivityId,
+			&i.Name,
+			&i.WeightUnit,
+			&reps,
+			&weights,
+		); err != nil {
+			return errors.Wrap(err, "failed to scan exercise")
+		}
+		for index := range reps {
+			i.Sets = append(i.Sets, model.ExerciseSet{
+				Reps:   reps[index],
+				Weight: weights[index],
+			})
+		}
+		exercises[i.ActivityId] = append(exercises[i.ActivityId], i)
+	}
+	if err := rows.Err(); err != nil {
+		return errors.Wrap(err, "failed to list exercises")
+	}
+
+	for index := range activities {
+		activities[index].Exercises = exercises[activities[index].ID]
+	}
+	return nil
+}
@@ -3,6 +3,10 @@ package repository
 import (
 	"context"
 	"fit-byte/internal/activity/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/helper"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/jackc/pgx/v5"
@@ -18,57 +22,174 @@ func NewActivityRepository(pool *pgxpool.Pool) *ActivityRepository {
 	return &ActivityRepository{pool: pool}
 }
 
+const activityColumns = `id, user_id, activity_type, done_at, duration_in_minutes, calories_burned, calorie_model, met_value, weight_kg, distance_meters, elevation_gain_meters, avg_heart_rate, max_heart_rate, notes, deleted_at, created_at, updated_at`
+
+func scanActivity(row pgx.Row) (model.Activity, error) {
+	var i model.Activity
+	err := row.Scan(
+		&i.ID,
+		&i.UserId,
+		&i.ActivityType,
+		&i.DoneAt,
+		&i.DurationInMinutes,
+		&i.CaloriesBurned,
+		&i.CalorieModel,
+		&i.MetValue,
+		&i.WeightKg,
+		&i.DistanceMeters,
+		&i.ElevationGainMeters,
+		&i.AvgHeartRate,
+		&i.MaxHeartRate,
+		&i.Notes,
+		&i.DeletedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const createActivity = `-- name: CreateActivity :one
 INSERT INTO activities (
   activity_type,
   done_at,
   duration_in_minutes,
   calories_burned,
+  calorie_model,
+  met_value,
+  weight_kg,
+  distance_meters,
+  elevation_gain_meters,
+  avg_heart_rate,
+  max_heart_rate,
+  notes,
   user_id
 ) VALUES (
-  $1, $2, $3, $4, $5
-) RETURNING id, user_id, activity_type, done_at, duration_in_minutes, calories_burned, created_at, updated_at
-`
+  $1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13
+) RETURNING ` + activityColumns
 
 type CreateActivityParams struct {
-	ActivityType      model.ActivityTypeEnum
-	DoneAt            time.Time
-	DurationInMinutes int
-	CaloriesBurned    int
-	UserId            int
+	ActivityType        model.ActivityTypeEnum
+	DoneAt              time.Time
+	DurationInMinutes   int
+	CaloriesBurned      int
+	CalorieModel        string
+	MetValue            *float64
+	WeightKg            *float64
+	DistanceMeters      *float64
+	ElevationGainMeters *float64
+	AvgHeartRate        *int
+	MaxHeartRate        *int
+	Notes               *string
+	Exercises           []model.Exercise
+	UserId              int
 }
 
-func (r *ActivityRepository) CreateActivity(ctx context.Context, arg CreateActivityParams) (model.Activity, error) {
-	row := r.pool.QueryRow(ctx, createActivity,
+// CreateActivity stores the activity together with its exercises. A non-nil
+// limit caps the activities the user keeps outside the trash, see
+// checkActivityLimit.
+func (r *ActivityRepository) CreateActivity(ctx context.Context, arg CreateActivityParams, limit *int) (model.Activity, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Activity{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkActivityLimit(ctx, tx, arg.UserId, 1, limit); err != nil {
+		return model.Activity{}, err
+	}
+
+	row := tx.QueryRow(ctx, createActivity,
 		arg.ActivityType,
 		arg.DoneAt,
 		arg.DurationInMinutes,
 		arg.CaloriesBurned,
+		arg.CalorieModel,
+		arg.MetValue,
+		arg.WeightKg,
+		arg.DistanceMeters,
+		arg.ElevationGainMeters,
+		arg.AvgHeartRate,
+		arg.MaxHeartRate,
+		arg.Notes,
 		arg.UserId,
 	)
-	var i model.Activity
-	err := row.Scan(
-		&i.ID,
-		&i.UserId,
-		&i.ActivityType,
-		&i.DoneAt,
-		&i.DurationInMinutes,
-		&i.CaloriesBurned,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+	activity, err := scanActivity(row)
+	if err != nil {
+		return model.Activity{}, err
+	}
+
+	if len(arg.Exercises) > 0 {
+		activity.Exercises, err = createExercises(ctx, tx, activity.ID, arg.Exercises)
+		if err != nil {
+			return model.Activity{}, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Activity{}, err
+	}
+	return activity, nil
+}
+
+// CreateActivities inserts all activities of a user in a single transaction.
+func (r *ActivityRepository) CreateActivities(ctx context.Context, userId int, args []CreateActivityParams, limit *int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkActivityLimit(ctx, tx, userId, len(args), limit); err != nil {
+		return err
+	}
+
+	batch := &pgx.Batch{}
+	for _, arg := range args {
+		batch.Queue(createActivity,
+			arg.ActivityType,
+			arg.DoneAt,
+			arg.DurationInMinutes,
+			arg.CaloriesBurned,
+			arg.CalorieModel,
+			arg.MetValue,
+			arg.WeightKg,
+			arg.DistanceMeters,
+			arg.ElevationGainMeters,
+			arg.AvgHeartRate,
+			arg.MaxHeartRate,
+			arg.Notes,
+			arg.UserId,
+		)
+	}
+
+	results := tx.SendBatch(ctx, batch)
+	for range args {
+		if _, err := results.Exec(); err != nil {
+			results.Close()
+			return errors.Wrap(err, "failed to execute insert statements")
+		}
+	}
+	if err := results.Close(); err != nil {
+		return errors.Wrap(err, "failed to execute insert statements")
+	}
+
+	return tx.Commit(ctx)
 }
 
 const listActivities = `-- name: ListActivities :many
-SELECT id, user_id, activity_type, done_at, duration_in_minutes, calories_burned, created_at, updated_at FROM activities
-WHERE ($3::enum_activity_types IS NULL OR activity_type = $3::enum_activity_types)
+SELECT ` + activityColumns + ` FROM activities
+WHERE ($3::varchar IS NULL OR activity_type = $3::varchar)
   AND ($4::timestamptz IS NULL OR done_at >= $4::timestamptz)
   AND ($5::timestamptz IS NULL OR done_at <= $5::timestamptz)
   AND ($6::int IS NULL OR calories_burned >= $6::int)
   AND ($7::int IS NULL OR calories_burned <= $7::int)
   AND (user_id = $8::bigint)
-ORDER BY id
+  AND deleted_at IS NULL
+  AND ($9::numeric IS NULL OR distance_meters >= $9::numeric)
+  AND ($10::numeric IS NULL OR distance_meters <= $10::numeric)
+  AND ($11::int IS NULL OR avg_heart_rate >= $11::int)
+  AND ($12::int IS NULL OR avg_heart_rate <= $12::int)
+ORDER BY %s
 LIMIT $1
 OFFSET $2
 `
@@ -81,11 +202,39 @@ type ListActivitiesParams struct {
 	DoneAtTo          *time.Time
 	CaloriesBurnedMin *int
 	CaloriesBurnedMax *int
+	DistanceMetersMin *float64
+	DistanceMetersMax *float64
+	AvgHeartRateMin   *int
+	AvgHeartRateMax   *int
 	UserId            int
+	Sort              []model.ActivitySort
+}
+
+// orderByClause builds an ORDER BY list from whitelisted sort columns, breaking
+// ties on id so paging stays stable.
+func orderByClause(sorts []model.ActivitySort) string {
+	if len(sorts) == 0 {
+		return "id"
+	}
+
+	clauses := make([]string, 0, len(sorts)+1)
+	for _, sort := range sorts {
+		clauses = append(clauses, sortClause(sort.Column, sort.Desc))
+	}
+	clauses = append(clauses, sortClause("id", sorts[0].Desc))
+	return strings.Join(clauses, ", ")
+}
+
+func sortClause(column string, desc bool) string {
+	if desc {
+		return column + " DESC"
+	}
+	return column + " ASC"
 }
 
 func (r *ActivityRepository) ListActivities(ctx context.Context, arg ListActivitiesParams) ([]model.Activity, error) {
-	rows, err := r.pool.Query(ctx, listActivities,
+	query := fmt.Sprintf(listActivities, orderByClause(arg.Sort))
+	rows, err := r.pool.Query(ctx, query,
 		arg.Limit,
 		arg.Offset,
 		arg.ActivityType,
@@ -94,6 +243,10 @@ func (r *ActivityRepository) ListActivities(ctx context.Context, arg ListActivit
 		arg.CaloriesBurnedMin,
 		arg.CaloriesBurnedMax,
 		arg.UserId,
+		arg.DistanceMetersMin,
+		arg.DistanceMetersMax,
+		arg.AvgHeartRateMin,
+		arg.AvgHeartRateMax,
 	)
 	if err != nil {
 		return nil, err
@@ -101,16 +254,247 @@ func (r *ActivityRepository) ListActivities(ctx context.Context, arg ListActivit
 	defer rows.Close()
 	var items []model.Activity
 	for rows.Next() {
-		var i model.Activity
+		i, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActivitiesByCursor = `-- name: ListActivitiesByCursor :many
+SELECT ` + activityColumns + ` FROM activities
+WHERE ($2::varchar IS NULL OR activity_type = $2::varchar)
+  AND ($3::timestamptz IS NULL OR done_at >= $3::timestamptz)
+  AND ($4::timestamptz IS NULL OR done_at <= $4::timestamptz)
+  AND ($5::int IS NULL OR calories_burned >= $5::int)
+  AND ($6::int IS NULL OR calories_burned <= $6::int)
+  AND (user_id = $7::bigint)
+  AND deleted_at IS NULL
+  AND ($8::timestamptz IS NULL OR (done_at, id) < ($8::timestamptz, $9::bigint))
+  AND ($10::numeric IS NULL OR distance_meters >= $10::numeric)
+  AND ($11::numeric IS NULL OR distance_meters <= $11::numeric)
+  AND ($12::int IS NULL OR avg_heart_rate >= $12::int)
+  AND ($13::int IS NULL OR avg_heart_rate <= $13::int)
+ORDER BY done_at DESC, id DESC
+LIMIT $1
+`
+
+type ListActivitiesByCursorParams struct {
+	Limit             int
+	ActivityType      *model.ActivityTypeEnum
+	DoneAtFrom        *time.Time
+	DoneAtTo          *time.Time
+	CaloriesBurnedMin *int
+	CaloriesBurnedMax *int
+	DistanceMetersMin *float64
+	DistanceMetersMax *float64
+	AvgHeartRateMin   *int
+	AvgHeartRateMax   *int
+	UserId            int
+	AfterDoneAt       *time.Time
+	AfterId           *int
+}
+
+func (r *ActivityRepository) ListActivitiesByCursor(ctx context.Context, arg ListActivitiesByCursorParams) ([]model.Activity, error) {
+	rows, err := r.pool.Query(ctx, listActivitiesByCursor,
+		arg.Limit,
+		arg.ActivityType,
+		arg.DoneAtFrom,
+		arg.DoneAtTo,
+		arg.CaloriesBurnedMin,
+		arg.CaloriesBurnedMax,
+		arg.UserId,
+		arg.AfterDoneAt,
+		arg.AfterId,
+		arg.DistanceMetersMin,
+		arg.DistanceMetersMax,
+		arg.AvgHeartRateMin,
+		arg.AvgHeartRateMax,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.Activity
+	for rows.Next() {
+		i, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const streamActivities = `-- name: StreamActivities :many
+SELECT ` + activityColumns + ` FROM activities
+WHERE ($1::varchar IS NULL OR activity_type = $1::varchar)
+  AND ($2::timestamptz IS NULL OR done_at >= $2::timestamptz)
+  AND ($3::timestamptz IS NULL OR done_at <= $3::timestamptz)
+  AND ($4::int IS NULL OR calories_burned >= $4::int)
+  AND ($5::int IS NULL OR calories_burned <= $5::int)
+  AND (user_id = $6::bigint)
+  AND deleted_at IS NULL
+  AND ($7::numeric IS NULL OR distance_meters >= $7::numeric)
+  AND ($8::numeric IS NULL OR distance_meters <= $8::numeric)
+  AND ($9::int IS NULL OR avg_heart_rate >= $9::int)
+  AND ($10::int IS NULL OR avg_heart_rate <= $10::int)
+ORDER BY done_at, id
+`
+
+type StreamActivitiesParams struct {
+	ActivityType      *model.ActivityTypeEnum
+	DoneAtFrom        *time.Time
+	DoneAtTo          *time.Time
+	CaloriesBurnedMin *int
+	CaloriesBurnedMax *int
+	DistanceMetersMin *float64
+	DistanceMetersMax *float64
+	AvgHeartRateMin   *int
+	AvgHeartRateMax   *int
+	UserId            int
+}
+
+// StreamActivities calls fn for every matching activity as rows arrive from
+// the connection, so the whole result set is never held in memory.
+func (r *ActivityRepository) StreamActivities(ctx context.Context, arg StreamActivitiesParams, fn func(model.Activity) error) error {
+	rows, err := r.pool.Query(ctx, streamActivities,
+		arg.ActivityType,
+		arg.DoneAtFrom,
+		arg.DoneAtTo,
+		arg.CaloriesBurnedMin,
+		arg.CaloriesBurnedMax,
+		arg.UserId,
+		arg.DistanceMetersMin,
+		arg.DistanceMetersMax,
+		arg.AvgHeartRateMin,
+		arg.AvgHeartRateMax,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+	for rows.Next() {
+		i, err := scanActivity(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(i); err != nil {
+			return err
+		}
+	}
+	return rows.Err()
+}
+
+const listActivityStats = `-- name: ListActivityStats :many
+SELECT
+  date_trunc($1::text, done_at AT TIME ZONE 'UTC') AS period_start,
+  CASE WHEN $2::bool THEN activity_type END AS activity_type,
+  SUM(duration_in_minutes)::bigint AS total_duration_in_minutes,
+  SUM(calories_burned)::bigint AS total_calories_burned,
+  COUNT(*) AS session_count
+FROM activities
+WHERE (user_id = $3::bigint)
+  AND deleted_at IS NULL
+  AND ($4::varchar IS NULL OR activity_type = $4::varchar)
+  AND ($5::timestamptz IS NULL OR done_at >= $5::timestamptz)
+  AND ($6::timestamptz IS NULL OR done_at <= $6::timestamptz)
+GROUP BY 1, 2
+ORDER BY 1, 2
+`
+
+type ListActivityStatsParams struct {
+	Period       string
+	GroupByType  bool
+	UserId       int
+	ActivityType *model.ActivityTypeEnum
+	DoneAtFrom   *time.Time
+	DoneAtTo     *time.Time
+}
+
+func (r *ActivityRepository) ListActivityStats(ctx context.Context, arg ListActivityStatsParams) ([]model.ActivityStat, error) {
+	rows, err := r.pool.Query(ctx, listActivityStats,
+		arg.Period,
+		arg.GroupByType,
+		arg.UserId,
+		arg.ActivityType,
+		arg.DoneAtFrom,
+		arg.DoneAtTo,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.ActivityStat
+	for rows.Next() {
+		var i model.ActivityStat
+		if err := rows.Scan(
+			&i.PeriodStart,
+			&i.ActivityType,
+			&i.TotalDurationInMinutes,
+			&i.TotalCaloriesBurned,
+			&i.SessionCount,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listActiveUserIds = `-- name: ListActiveUserIds :many
+SELECT DISTINCT user_id FROM activities
+WHERE deleted_at IS NULL
+ORDER BY user_id
+`
+
+// ListActiveUserIds returns every user with at least one activity.
+func (r *ActivityRepository) ListActiveUserIds(ctx context.Context) ([]int, error) {
+	rows, err := r.pool.Query(ctx, listActiveUserIds)
+	if err != nil {
+		return nil, err
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+const listActivityTotals = `-- name: ListActivityTotals :many
+SELECT
+  activity_type,
+  SUM(duration_in_minutes)::bigint AS total_duration_in_minutes,
+  SUM(calories_burned)::bigint AS total_calories_burned,
+  COUNT(*) AS session_count
+FROM activities
+WHERE (user_id = $1::bigint)
+  AND deleted_at IS NULL
+GROUP BY activity_type
+ORDER BY activity_type
+`
+
+func (r *ActivityRepository) ListActivityTotals(ctx context.Context, userId int) ([]model.ActivityTotal, error) {
+	rows, err := r.pool.Query(ctx, listActivityTotals, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.ActivityTotal
+	for rows.Next() {
+		var i model.ActivityTotal
 		if err := rows.Scan(
-			&i.ID,
-			&i.UserId,
 			&i.ActivityType,
-			&i.DoneAt,
-			&i.DurationInMinutes,
-			&i.CaloriesBurned,
-			&i.CreatedAt,
-			&i.UpdatedAt,
+			&i.TotalDurationInMinutes,
+			&i.TotalCaloriesBurned,
+			&i.SessionCount,
 		); err != nil {
 			return nil, err
 		}
@@ -122,10 +506,69 @@ func (r *ActivityRepository) ListActivities(ctx context.Context, arg ListActivit
 	return items, nil
 }
 
+const getActivityStreak = `-- name: GetActivityStreak :one
+WITH
+tz AS (
+  SELECT time_zone FROM users WHERE (id = $1::bigint)
+),
+buckets AS (
+  SELECT DISTINCT date_trunc($2::text, a.done_at AT TIME ZONE tz.time_zone)::date AS bucket
+  FROM activities a
+  CROSS JOIN tz
+  WHERE (a.user_id = $1::bigint)
+    AND a.deleted_at IS NULL
+),
+islands AS (
+  SELECT
+    MAX(bucket) AS last_bucket,
+    COUNT(*) AS length
+  FROM (
+    SELECT bucket, bucket - (ROW_NUMBER() OVER (ORDER BY bucket))::int * $3::int AS grp
+    FROM buckets
+  ) b
+  GROUP BY grp
+),
+today AS (
+  SELECT date_trunc($2::text, NOW() AT TIME ZONE tz.time_zone)::date AS bucket FROM tz
+)
+SELECT
+  COALESCE((SELECT time_zone FROM tz), 'UTC'),
+  COALESCE(MAX(i.length) FILTER (WHERE i.last_bucket >= t.bucket - $3::int), 0),
+  COALESCE(MAX(i.length), 0),
+  MAX(i.last_bucket)::timestamp
+FROM islands i
+LEFT JOIN today t ON true
+`
+
+type GetActivityStreakParams struct {
+	UserId int
+	// Unit is the date_trunc field of a bucket, "day" or "week".
+	Unit string
+	// StepDays is the length of a bucket in days.
+	StepDays int
+}
+
+// GetActivityStreak finds the islands of consecutive buckets holding an
+// activity. A streak is current when its last bucket is this one or the one
+// before, so it is not broken until a whole bucket passes without activity.
+func (r *ActivityRepository) GetActivityStreak(ctx context.Context, arg GetActivityStreakParams) (string, model.ActivityStreak, error) {
+	row := r.pool.QueryRow(ctx, getActivityStreak, arg.UserId, arg.Unit, arg.StepDays)
+	var timeZone string
+	var i model.ActivityStreak
+	err := row.Scan(
+		&timeZone,
+		&i.Current,
+		&i.Longest,
+		&i.LastActive,
+	)
+	return timeZone, i, err
+}
+
 const getActivity = `-- name: GetActivity :one
-SELECT id, user_id, activity_type, done_at, duration_in_minutes, calories_burned, created_at, updated_at FROM activities
+SELECT ` + activityColumns + ` FROM activities
 WHERE (id = $1::bigint)
   AND (user_id = $2::bigint)
+  AND deleted_at IS NULL
 LIMIT 1
 `
 
@@ -136,18 +579,36 @@ type GetAndDeleteActivityParams struct {
 
 func (r *ActivityRepository) GetActivity(ctx context.Context, arg GetAndDeleteActivityParams) (model.Activity, error) {
 	row := r.pool.QueryRow(ctx, getActivity, arg.Id, arg.UserId)
-	var i model.Activity
-	err := row.Scan(
-		&i.ID,
-		&i.UserId,
-		&i.ActivityType,
-		&i.DoneAt,
-		&i.DurationInMinutes,
-		&i.CaloriesBurned,
-		&i.CreatedAt,
-		&i.UpdatedAt,
-	)
-	return i, err
+	return scanActivity(row)
+}
+
+const listActivitiesByIds = `-- name: ListActivitiesByIds :many
+SELECT ` + activityColumns + ` FROM activities
+WHERE (user_id = $1::bigint)
+  AND id = ANY($2::bigint[])
+  AND deleted_at IS NULL
+`
+
+// ListActivitiesByIds returns the user's activities with the given ids that
+// are not in the trash.
+func (r *ActivityRepository) ListActivitiesByIds(ctx context.Context, userId int, ids []int) ([]model.Activity, error) {
+	rows, err := r.pool.Query(ctx, listActivitiesByIds, userId, ids)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.Activity
+	for rows.Next() {
+		i, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
 }
 
 const queryUpdateActivity = `
@@ -157,13 +618,29 @@ const queryUpdateActivity = `
 			t.type,
 			t.duration,
 			t.calories_burned,
+			t.calorie_model,
+			t.met_value,
+			t.weight_kg,
+			t.distance_meters,
+			t.elevation_gain_meters,
+			t.avg_heart_rate,
+			t.max_heart_rate,
+			t.notes,
 			t.done_at,
 			t.updated_at
 		FROM (
 			VALUES (
-				@type::enum_activity_types,
+				@type::varchar,
 				@duration::INT,
 				@calories_burned::INT,
+				@calorie_model::VARCHAR,
+				@met_value::NUMERIC,
+				@weight_kg::NUMERIC,
+				@distance_meters::NUMERIC,
+				@elevation_gain_meters::NUMERIC,
+				@avg_heart_rate::INT,
+				@max_heart_rate::INT,
+				@notes::TEXT,
 				@done_at::TIMESTAMPTZ,
 				@updated_at::TIMESTAMPTZ
 			)
@@ -171,6 +648,14 @@ const queryUpdateActivity = `
 			type,
 			duration,
 			calories_burned,
+			calorie_model,
+			met_value,
+			weight_kg,
+			distance_meters,
+			elevation_gain_meters,
+			avg_heart_rate,
+			max_heart_rate,
+			notes,
 			done_at,
 			updated_at
 		)
@@ -180,63 +665,128 @@ const queryUpdateActivity = `
 		activity_type = COALESCE(payload.type, activities.activity_type),
 		duration_in_minutes = COALESCE(payload.duration, activities.duration_in_minutes),
 		calories_burned = COALESCE(payload.calories_burned, activities.calories_burned),
+		calorie_model = COALESCE(payload.calorie_model, activities.calorie_model),
+		met_value = CASE WHEN @recalculated::BOOL THEN payload.met_value ELSE activities.met_value END,
+		weight_kg = CASE WHEN @recalculated::BOOL THEN payload.weight_kg ELSE activities.weight_kg END,
+		distance_meters = CASE WHEN @distance_meters_set::BOOL THEN payload.distance_meters ELSE activities.distance_meters END,
+		elevation_gain_meters = CASE WHEN @elevation_gain_meters_set::BOOL THEN payload.elevation_gain_meters ELSE activities.elevation_gain_meters END,
+		avg_heart_rate = CASE WHEN @avg_heart_rate_set::BOOL THEN payload.avg_heart_rate ELSE activities.avg_heart_rate END,
+		max_heart_rate = CASE WHEN @max_heart_rate_set::BOOL THEN payload.max_heart_rate ELSE activities.max_heart_rate END,
+		notes = CASE WHEN @notes_set::BOOL THEN payload.notes ELSE activities.notes END,
 		done_at = COALESCE(payload.done_at, activities.done_at),
 		updated_at = COALESCE(payload.updated_at, activities.updated_at)
 	FROM payload
 	WHERE
 		activities.id = @activitiesId
+		AND activities.user_id = @userId
+		AND activities.deleted_at IS NULL
+		AND (@expectedUpdatedAt::TIMESTAMPTZ IS NULL OR activities.updated_at = @expectedUpdatedAt::TIMESTAMPTZ)
 	RETURNING
 		activities.id,
+		activities.user_id,
 		activities.activity_type,
 		activities.done_at,
 		activities.duration_in_minutes,
 		activities.calories_burned,
+		activities.calorie_model,
+		activities.met_value,
+		activities.weight_kg,
+		activities.distance_meters,
+		activities.elevation_gain_meters,
+		activities.avg_heart_rate,
+		activities.max_heart_rate,
+		activities.notes,
+		activities.deleted_at,
 		activities.created_at,
 		activities.updated_at
 		;
 `
 
+// PatchActivitiesParams leaves nil fields unchanged. Calorie fields are only
+// written when Recalculated is set, and Nullable fields only when Set, which
+// allows clearing them. A non-nil ExpectedUpdatedAt turns the update into a
+// compare-and-swap that matches no row when the activity changed meanwhile.
+// A non-nil Exercises replaces all exercises of the activity.
 type PatchActivitiesParams struct {
-	ActivityType      model.ActivityTypeEnum
-	DoneAt            time.Time
-	UpdatedAt         time.Time
-	DurationInMinutes int
-	CaloriesBurned    int
-	ActivityId        int
-	UserId            int
+	ActivityType        *model.ActivityTypeEnum
+	DoneAt              *time.Time
+	UpdatedAt           time.Time
+	DurationInMinutes   *int
+	Recalculated        bool
+	CaloriesBurned      *int
+	CalorieModel        *string
+	MetValue            *float64
+	WeightKg            *float64
+	DistanceMeters      helper.Nullable[float64]
+	ElevationGainMeters helper.Nullable[float64]
+	AvgHeartRate        helper.Nullable[int]
+	MaxHeartRate        helper.Nullable[int]
+	Notes               helper.Nullable[string]
+	Exercises           *[]model.Exercise
+	ExpectedUpdatedAt   *time.Time
+	ActivityId          int
+	UserId              int
 }
 
 func (r *ActivityRepository) UpdateActivityRepo(ctx context.Context, arg PatchActivitiesParams) (*model.Activity, error) {
-	var activity model.Activity
-
 	args := pgx.NamedArgs{
-		"type":            arg.ActivityType,
-		"duration":        arg.DurationInMinutes,
-		"calories_burned": arg.CaloriesBurned,
-		"done_at":         arg.DoneAt,
-		"updated_at":      arg.UpdatedAt,
-		"activitiesId":    arg.ActivityId,
-	}
-
-	err := r.pool.QueryRow(ctx, queryUpdateActivity, args).Scan(
-		&activity.ID,
-		&activity.ActivityType,
-		&activity.DoneAt,
-		&activity.DurationInMinutes,
-		&activity.CaloriesBurned,
-		&activity.CreatedAt,
-		&activity.UpdatedAt,
-	)
+		"type":                      arg.ActivityType,
+		"duration":                  arg.DurationInMinutes,
+		"recalculated":              arg.Recalculated,
+		"calories_burned":           arg.CaloriesBurned,
+		"calorie_model":             arg.CalorieModel,
+		"met_value":                 arg.MetValue,
+		"weight_kg":                 arg.WeightKg,
+		"distance_meters":           arg.DistanceMeters.Value,
+		"distance_meters_set":       arg.DistanceMeters.Set,
+		"elevation_gain_meters":     arg.ElevationGainMeters.Value,
+		"elevation_gain_meters_set": arg.ElevationGainMeters.Set,
+		"avg_heart_rate":            arg.AvgHeartRate.Value,
+		"avg_heart_rate_set":        arg.AvgHeartRate.Set,
+		"max_heart_rate":            arg.MaxHeartRate.Value,
+		"max_heart_rate_set":        arg.MaxHeartRate.Set,
+		"notes":                     arg.Notes.Value,
+		"notes_set":                 arg.Notes.Set,
+		"done_at":                   arg.DoneAt,
+		"updated_at":                arg.UpdatedAt,
+		"activitiesId":              arg.ActivityId,
+		"userId":                    arg.UserId,
+		"expectedUpdatedAt":         arg.ExpectedUpdatedAt,
+	}
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	activity, err := scanActivity(tx.QueryRow(ctx, queryUpdateActivity, args))
 
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to execute Update statements")
 	}
 
+	if arg.Exercises != nil {
+		if _, err := tx.Exec(ctx, deleteExercises, activity.ID); err != nil {
+			return nil, errors.Wrap(err, "failed to delete exercises")
+		}
+		activity.Exercises, err = createExercises(ctx, tx, activity.ID, *arg.Exercises)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+
 	return &activity, nil
 }
 
 const queryDeleteActivity = `
-DELETE FROM Activities WHERE user_id = @user_id AND id = @activityId
+UPDATE activities SET deleted_at = NOW()
+WHERE user_id = @userId AND id = @activityId AND deleted_at IS NULL
+RETURNING activity_type
 `
 
 type DeleteActivitiesParams struct {
@@ -244,16 +794,155 @@ type DeleteActivitiesParams struct {
 	UserId     int
 }
 
-func (r *ActivityRepository) DeleteActivity(ctx context.Context, arg DeleteActivitiesParams) error {
+// DeleteActivity moves an activity to the trash and returns its type. It
+// returns pgx.ErrNoRows when there is no such activity outside the trash.
+func (r *ActivityRepository) DeleteActivity(ctx context.Context, arg DeleteActivitiesParams) (model.ActivityTypeEnum, error) {
 	args := pgx.NamedArgs{
-		"user_id": arg.UserId,
-		"id":      arg.ActivityId,
+		"userId":     arg.UserId,
+		"activityId": arg.ActivityId,
 	}
 
-	_, err := r.pool.Exec(ctx, queryDeleteActivity, args)
+	var activityType model.ActivityTypeEnum
+	err := r.pool.QueryRow(ctx, queryDeleteActivity, args).Scan(&activityType)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return "", pgx.ErrNoRows
+	}
+	if err != nil {
+		return "", errors.Wrap(err, "failed to execute delete statements")
+	}
+
+	return activityType, nil
+}
+
+const listDeletedActivities = `-- name: ListDeletedActivities :many
+SELECT ` + activityColumns + ` FROM activities
+WHERE (user_id = $3::bigint)
+  AND deleted_at IS NOT NULL
+ORDER BY deleted_at DESC, id DESC
+LIMIT $1
+OFFSET $2
+`
+
+type ListDeletedActivitiesParams struct {
+	Limit  int
+	Offset int
+	UserId int
+}
+
+func (r *ActivityRepository) ListDeletedActivities(ctx context.Context, arg ListDeletedActivitiesParams) ([]model.Activity, error) {
+	rows, err := r.pool.Query(ctx, listDeletedActivities,
+		arg.Limit,
+		arg.Offset,
+		arg.UserId,
+	)
 	if err != nil {
-		return errors.Wrap(err, "failed to execute delete statements")
+		return nil, err
 	}
+	defer rows.Close()
+	var items []model.Activity
+	for rows.Next() {
+		i, err := scanActivity(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const restoreActivity = `-- name: RestoreActivity :one
+UPDATE activities SET deleted_at = NULL
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+  AND deleted_at IS NOT NULL
+RETURNING ` + activityColumns
 
+func (r *ActivityRepository) RestoreActivity(ctx context.Context, arg GetAndDeleteActivityParams, limit *int) (model.Activity, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return model.Activity{}, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := checkActivityLimit(ctx, tx, arg.UserId, 1, limit); err != nil {
+		return model.Activity{}, err
+	}
+
+	activity, err := scanActivity(tx.QueryRow(ctx, restoreActivity, arg.Id, arg.UserId))
+	if err != nil {
+		return model.Activity{}, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return model.Activity{}, err
+	}
+	return activity, nil
+}
+
+const purgeDeletedActivities = `-- name: PurgeDeletedActivities :execrows
+DELETE FROM activities
+WHERE deleted_at IS NOT NULL
+  AND deleted_at < $1::timestamptz
+`
+
+// PurgeDeletedActivities permanently removes activities trashed before deletedBefore.
+func (r *ActivityRepository) PurgeDeletedActivities(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, purgeDeletedActivities, deletedBefore)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to purge deleted activities")
+	}
+	return tag.RowsAffected(), nil
+}
+
+const countActivities = `-- name: CountActivities :one
+SELECT COUNT(*) FROM activities
+WHERE (user_id = $1::bigint)
+  AND deleted_at IS NULL
+`
+
+const lockUser = `-- name: LockUser :exec
+SELECT id FROM users
+WHERE (id = $1::bigint)
+FOR UPDATE
+`
+
+// checkActivityLimit returns customErrors.ErrForbidden when adding activities
+// would take the user over limit. It locks the user row first, so concurrent
+// inserts for the same user are counted one after the other.
+func checkActivityLimit(ctx context.Context, tx pgx.Tx, userId int, adding int, limit *int) error {
+	if limit == nil {
+		return nil
+	}
+
+	if _, err := tx.Exec(ctx, lockUser, userId); err != nil {
+		return errors.Wrap(err, "failed to lock user")
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, countActivities, userId).Scan(&count); err != nil {
+		return errors.Wrap(err, "failed to count activities")
+	}
+	if count+adding > *limit {
+		return customErrors.ErrForbidden
+	}
 	return nil
 }
+
+const getUserWeight = `-- name: GetUserWeight :one
+SELECT weight, weight_unit FROM users
+WHERE (id = $1::bigint)
+LIMIT 1
+`
+
+func (r *ActivityRepository) GetUserWeight(ctx context.Context, userId int) (model.UserWeight, error) {
+	row := r.pool.QueryRow(ctx, getUserWeight, userId)
+	var i model.UserWeight
+	err := row.Scan(
+		&i.Weight,
+		&i.WeightUnit,
+	)
+	return i, err
+}
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"context"
+	"fit-byte/internal/activity/model"
+	customErrors "fit-byte/pkg/custom-errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type ActivityTypeRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewActivityTypeRepository(pool *pgxpool.Pool) *ActivityTypeRepository {
+	return &ActivityTypeRepository{pool: pool}
+}
+
+const activityTypeColumns = `id, user_id, name, category, calories_per_minute, met_value::float8, created_at, updated_at`
+
+func scanActivityType(row pgx.Row) (model.ActivityType, error) {
+	var i model.ActivityType
+	err := row.Scan(
+		&i.ID,
+		&i.UserId,
+		&i.Name,
+		&i.Category,
+		&i.CaloriesPerMinute,
+		&i.MetValue,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const listGlobalActivityTypes = `-- name: ListGlobalActivityTypes :many
+SELECT ` + activityTypeColumns + ` FROM activity_types
+WHERE user_id IS NULL
+ORDER BY id
+`
+
+func (r *ActivityTypeRepository) ListGlobalActivityTypes(ctx context.Context) ([]model.ActivityType, error) {
+	rows, err := r.pool.Query(ctx, listGlobalActivityTypes)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActivityTypes(rows)
+}
+
+const listUserActivityTypes = `-- name: ListUserActivityTypes :many
+SELECT ` + activityTypeColumns + ` FROM activity_types
+WHERE (user_id = $1::bigint)
+ORDER BY id
+`
+
+// ListUserActivityTypes returns the custom types of the user.
+func (r *ActivityTypeRepository) ListUserActivityTypes(ctx context.Context, userId int) ([]model.ActivityType, error) {
+	rows, err := r.pool.Query(ctx, listUserActivityTypes, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanActivityTypes(rows)
+}
+
+func scanActivityTypes(rows pgx.Rows) ([]model.ActivityType, error) {
+	items := []model.ActivityType{}
+	for rows.Next() {
+		i, err := scanActivityType(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserActivityTypeByName = `-- name: GetUserActivityTypeByName :one
+SELECT ` + activityTypeColumns + ` FROM activity_types
+WHERE (user_id = $1::bigint)
+  AND (name = $2::varchar)
+LIMIT 1
+`
+
+// GetUserActivityTypeByName returns a custom type of the user. Global types
+// are not matched.
+func (r *ActivityTypeRepository) GetUserActivityTypeByName(ctx context.Context, userId int, name model.ActivityTypeEnum) (model.ActivityType, error) {
+	row := r.pool.QueryRow(ctx, getUserActivityTypeByName, userId, name)
+	return scanActivityType(row)
+}
+
+const createActivityType = `-- name: CreateActivityType :one
+INSERT INTO activity_types (
+  name,
+  category,
+  calories_per_minute,
+  met_value,
+  user_id
+) VALUES (
+  $1, $2, $3, $4, $5
+) RETURNING ` + activityTypeColumns
+
+type CreateActivityTypeParams struct {
+	Name              model.ActivityTypeEnum
+	Category          model.ActivityCategoryEnum
+	CaloriesPerMinute *int
+	MetValue          *float64
+	UserId            int
+}
+
+// CreateActivityType returns customErrors.ErrConflict when the user already
+// has a type with that name.
+func (r *ActivityTypeRepository) CreateActivityType(ctx context.Context, arg CreateActivityTypeParams) (model.ActivityType, error) {
+	row := r.pool.QueryRow(ctx, createActivityType,
+		arg.Name,
+		arg.Category,
+		arg.CaloriesPerMinute,
+		arg.MetValue,
+		arg.UserId,
+	)
+	i, err := scanActivityType(row)
+	if customErrors.GetPgErrCode(err) == customErrors.UniqueViolation {
+		return i, errors.Wrap(customErrors.ErrConflict, "activity type already exists")
+	}
+	return i, err
+}
+
+const getCustomActivityType = `-- name: GetCustomActivityType :one
+SELECT ` + activityTypeColumns + ` FROM activity_types
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+LIMIT 1
+`
+
+func (r *ActivityTypeRepository) GetCustomActivityType(ctx context.Context, id int, userId int) (model.ActivityType, error) {
+	row := r.pool.QueryRow(ctx, getCustomActivityType, id, userId)
+	return scanActivityType(row)
+}
+
+const deleteActivityType = `-- name: DeleteActivityType :exec
+DELETE FROM activity_types
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+`
+
+// DeleteActivityType removes a custom type. Global types cannot be deleted.
+func (r *ActivityTypeRepository) DeleteActivityType(ctx context.Context, id int, userId int) error {
+	_, err := r.pool.Exec(ctx, deleteActivityType, id, userId)
+	return err
+}
+
+const isActivityTypeInUse = `-- name: IsActivityTypeInUse :one
+SELECT
+  EXISTS (SELECT 1 FROM activities WHERE (user_id = $1::bigint) AND activity_type = $2::varchar)
+  OR EXISTS (SELECT 1 FROM planned_workouts WHERE (user_id = $1::bigint) AND activity_type = $2::varchar)
+  OR EXISTS (SELECT 1 FROM goals WHERE (user_id = $1::bigint) AND activity_type = $2::varchar)
+  OR EXISTS (SELECT 1 FROM personal_records WHERE (user_id = $1::bigint) AND activity_type = $2::varchar)
+`
+
+// IsActivityTypeInUse reports whether any of the user's activities, including
+// those in the trash, planned workouts, goals or personal records has the type.
+func (r *ActivityTypeRepository) IsActivityTypeInUse(ctx context.Context, userId int, name model.ActivityTypeEnum) (bool, error) {
+	var inUse bool
+	err := r.pool.QueryRow(ctx, isActivityTypeInUse, userId, name).Scan(&inUse)
+	return inUse, err
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"context"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model"
+	customErrors "fit-byte/pkg/custom-errors"
+
+	"github.com/pkg/errors"
+)
+
+// requireStrength rejects exercises on activity types that are not strength
+// training.
+func (c *ActivityUseCase) requireStrength(ctx context.Context, name model.ActivityTypeEnum, userId int) error {
+	activityType, err := c.activityTypes.Resolve(ctx, userId, name)
+	if err != nil {
+		return err
+	}
+	if activityType.Category != model.ActivityCategoryEnumStrength {
+		return errors.Wrap(customErrors.ErrBadRequest, "exercises are only allowed for strength activity types")
+	}
+	return nil
+}
+
+// toExercises converts the requested exercises of an activity. The weights
+// are taken to be in the user's current weight unit, which defaults to KG.
+func (c *ActivityUseCase) toExercises(ctx context.Context, name model.ActivityTypeEnum, requests []dto.ExerciseRequest, userId int) ([]model.Exercise, error) {
+	if len(requests) == 0 {
+		return nil, nil
+	}
+
+	if err := c.requireStrength(ctx, name, userId); err != nil {
+		return nil, err
+	}
+
+	weight, err := c.activityRepo.GetUserWeight(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user weight")
+	}
+	weightUnit := model.WeightUnitEnumKg
+	if weight.WeightUnit != nil {
+		weightUnit = model.WeightUnitEnum(*weight.WeightUnit)
+	}
+
+	exercises := make([]model.Exercise, 0, len(requests))
+	for _, request := range requests {
+		sets := make([]model.ExerciseSet, 0, len(request.Sets))
+		for _, set := range request.Sets {
+			sets = append(sets, model.ExerciseSet{
+				Reps:   set.Reps,
+				Weight: set.Weight,
+			})
+		}
+		exercises = append(exercises, model.Exercise{
+			Name:       request.Name,
+			WeightUnit: weightUnit,
+			Sets:       sets,
+		})
+	}
+	return exercises, nil
+}
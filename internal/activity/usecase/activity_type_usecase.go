@@ -0,0 +1,165 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fit-byte/internal/activity/dto"
+	"fit-byte/internal/activity/model"
+	"fit-byte/internal/activity/repository"
+	customErrors "fit-byte/pkg/custom-errors"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// ActivityTypeUseCase keeps the global activity types in memory and looks up
+// custom types in the database, scoped to the requesting user. It is shared
+// by the activity usecases and must be used as a pointer.
+type ActivityTypeUseCase struct {
+	activityTypeRepo repository.ActivityTypeRepository
+
+	mu     sync.RWMutex
+	global map[model.ActivityTypeEnum]model.ActivityType
+}
+
+func NewActivityTypeUseCase(activityTypeRepo repository.ActivityTypeRepository) *ActivityTypeUseCase {
+	return &ActivityTypeUseCase{
+		activityTypeRepo: activityTypeRepo,
+		global:           map[model.ActivityTypeEnum]model.ActivityType{},
+	}
+}
+
+// Load replaces the cache with the global types stored in the database.
+func (c *ActivityTypeUseCase) Load(ctx context.Context) error {
+	activityTypes, err := c.activityTypeRepo.ListGlobalActivityTypes(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to load activity types")
+	}
+
+	global := make(map[model.ActivityTypeEnum]model.ActivityType, len(activityTypes))
+	for _, activityType := range activityTypes {
+		global[activityType.Name] = activityType
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.global = global
+	return nil
+}
+
+// RunRefresh reloads the cache every interval until ctx is done.
+func (c *ActivityTypeUseCase) RunRefresh(ctx context.Context, interval time.Duration, log *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := c.Load(ctx); err != nil {
+				log.WithError(err).Error("failed to refresh activity types")
+			}
+		}
+	}
+}
+
+func (c *ActivityTypeUseCase) lookupGlobal(name model.ActivityTypeEnum) (model.ActivityType, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	activityType, ok := c.global[name]
+	return activityType, ok
+}
+
+// Resolve returns the global type or the user's own custom type with the
+// given name. Other users' custom types are reported as unknown.
+func (c *ActivityTypeUseCase) Resolve(ctx context.Context, userId int, name model.ActivityTypeEnum) (model.ActivityType, error) {
+	if activityType, ok := c.lookupGlobal(name); ok {
+		return activityType, nil
+	}
+
+	activityType, err := c.activityTypeRepo.GetUserActivityTypeByName(ctx, userId, name)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return model.ActivityType{}, errors.Wrapf(customErrors.ErrBadRequest, "unknown activity type %q", name)
+	}
+	if err != nil {
+		return model.ActivityType{}, errors.Wrap(err, "failed to get activity type")
+	}
+
+	return activityType, nil
+}
+
+// GetActivityTypes lists the global types followed by the user's custom types.
+func (c *ActivityTypeUseCase) GetActivityTypes(ctx context.Context, userId int) ([]model.ActivityType, error) {
+	custom, err := c.activityTypeRepo.ListUserActivityTypes(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list activity types")
+	}
+
+	c.mu.RLock()
+	activityTypes := make([]model.ActivityType, 0, len(c.global)+len(custom))
+	for _, activityType := range c.global {
+		activityTypes = append(activityTypes, activityType)
+	}
+	c.mu.RUnlock()
+
+	sort.Slice(activityTypes, func(i, j int) bool { return activityTypes[i].ID < activityTypes[j].ID })
+
+	return append(activityTypes, custom...), nil
+}
+
+func (c *ActivityTypeUseCase) CreateActivityType(ctx context.Context, request *dto.CreateActivityTypeRequest, userId int) (*model.ActivityType, error) {
+	name := model.ActivityTypeEnum(strings.TrimSpace(request.Name))
+	if len(name) < 2 {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "name must have at least 2 characters")
+	}
+
+	c.mu.RLock()
+	for globalName := range c.global {
+		if strings.EqualFold(string(globalName), string(name)) {
+			c.mu.RUnlock()
+			return nil, errors.Wrap(customErrors.ErrConflict, "activity type already exists")
+		}
+	}
+	c.mu.RUnlock()
+
+	activityType, err := c.activityTypeRepo.CreateActivityType(ctx, repository.CreateActivityTypeParams{
+		Name:              name,
+		Category:          request.Category,
+		CaloriesPerMinute: request.CaloriesPerMinute,
+		MetValue:          request.MetValue,
+		UserId:            userId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create activity type")
+	}
+
+	return &activityType, nil
+}
+
+// DeleteActivityType deletes a custom type that is no longer used.
+func (c *ActivityTypeUseCase) DeleteActivityType(ctx context.Context, activityTypeId int, userId int) error {
+	activityType, err := c.activityTypeRepo.GetCustomActivityType(ctx, activityTypeId, userId)
+	if err != nil {
+		return errors.Wrap(err, "failed to get activity type")
+	}
+
+	inUse, err := c.activityTypeRepo.IsActivityTypeInUse(ctx, userId, activityType.Name)
+	if err != nil {
+		return errors.Wrap(err, "failed to check activity type usage")
+	}
+	if inUse {
+		return errors.Wrap(customErrors.ErrConflict, "activity type is still used by activities, planned workouts, goals or records")
+	}
+
+	if err := c.activityTypeRepo.DeleteActivityType(ctx, activityTypeId, userId); err != nil {
+		return errors.Wrap(err, "failed to delete activity type")
+	}
+
+	return nil
+}
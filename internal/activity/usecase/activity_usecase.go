@@ -2,26 +2,104 @@ package usecase
 
 import (
 	"context"
+	"fmt"
 	"time"
 
+	achievementModel "fit-byte/internal/achievements/model"
 	"fit-byte/internal/activity/dto"
 	"fit-byte/internal/activity/model"
 	"fit-byte/internal/activity/repository"
+	recordModel "fit-byte/internal/records/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/helper"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
+const (
+	EXPORT_BATCH_SIZE = 100
+)
+
+// RecordDetector finds the personal records set by a saved activity, and
+// rebuilds them when activities are edited or removed.
+type RecordDetector interface {
+	DetectRecords(ctx context.Context, activity model.Activity) ([]recordModel.PersonalRecord, error)
+	RecomputeRecords(ctx context.Context, userId int, activityTypes []model.ActivityTypeEnum) ([]recordModel.PersonalRecord, error)
+}
+
+// AchievementEvaluator awards the achievements a user has earned so far.
+type AchievementEvaluator interface {
+	EvaluateAchievements(ctx context.Context, userId int) ([]achievementModel.UserAchievement, error)
+}
+
+// ActivityQuota caps the activities a user may keep outside the trash. A nil
+// limit means no cap.
+type ActivityQuota interface {
+	ActivityLimit(ctx context.Context, userId int) (*int, error)
+}
+
 type ActivityUseCase struct {
-	activityRepo repository.ActivityRepository
+	activityRepo         repository.ActivityRepository
+	activityTypes        *ActivityTypeUseCase
+	calorieEstimator     CalorieEstimator
+	recordDetector       RecordDetector
+	achievementEvaluator AchievementEvaluator
+	activityQuota        ActivityQuota
+	log                  *logrus.Logger
 }
 
-func NewActivityUseCase(activityRepo repository.ActivityRepository) *ActivityUseCase {
+func NewActivityUseCase(activityRepo repository.ActivityRepository, activityTypes *ActivityTypeUseCase, recordDetector RecordDetector, achievementEvaluator AchievementEvaluator, activityQuota ActivityQuota, log *logrus.Logger) *ActivityUseCase {
 	return &ActivityUseCase{
-		activityRepo,
+		activityRepo:         activityRepo,
+		activityTypes:        activityTypes,
+		calorieEstimator:     NewMETCalorieEstimator(FlatCalorieEstimator{}),
+		recordDetector:       recordDetector,
+		achievementEvaluator: achievementEvaluator,
+		activityQuota:        activityQuota,
+		log:                  log,
+	}
+}
+
+// afterSave updates the records and achievements derived from a saved activity
+// and returns the personal records it set. The activity is already committed,
+// so failing the request over its records would only make the client retry
+// and save it twice; those errors are logged instead.
+func (c *ActivityUseCase) afterSave(ctx context.Context, activity model.Activity) []recordModel.PersonalRecord {
+	records, err := c.recordDetector.DetectRecords(ctx, activity)
+	if err != nil {
+		c.log.WithError(err).WithField("activityId", activity.ID).Error("failed to detect personal records")
+	}
+
+	c.evaluateAchievements(ctx, activity.UserId)
+	return records
+}
+
+// evaluateAchievements awards new achievements after a write. Like afterSave it
+// only logs failures, the next write or the backfill command catches up.
+func (c *ActivityUseCase) evaluateAchievements(ctx context.Context, userId int) {
+	if _, err := c.achievementEvaluator.EvaluateAchievements(ctx, userId); err != nil {
+		c.log.WithError(err).WithField("userId", userId).Error("failed to evaluate achievements")
 	}
 }
 
+// recomputeRecords rebuilds the records of the activity types after activities
+// were edited or removed and returns those that changed. Like afterSave it only
+// logs failures.
+func (c *ActivityUseCase) recomputeRecords(ctx context.Context, userId int, activityTypes ...model.ActivityTypeEnum) []recordModel.PersonalRecord {
+	records, err := c.recordDetector.RecomputeRecords(ctx, userId, activityTypes)
+	if err != nil {
+		c.log.WithError(err).WithField("userId", userId).Error("failed to recompute personal records")
+		return nil
+	}
+	return records
+}
+
 func (c *ActivityUseCase) GetActivity(ctx context.Context, request *dto.GetActivityRequest, userid int) (*[]model.Activity, error) {
+	var sort []model.ActivitySort
+	if request.Sort != "" {
+		sort, _ = model.ParseActivitySort(request.Sort)
+	}
 
 	arg := repository.ListActivitiesParams{
 		Limit:             request.Limit,
@@ -31,7 +109,12 @@ func (c *ActivityUseCase) GetActivity(ctx context.Context, request *dto.GetActiv
 		DoneAtTo:          request.DoneAtTo,
 		CaloriesBurnedMin: request.CaloriesBurnedMin,
 		CaloriesBurnedMax: request.CaloriesBurnedMax,
+		DistanceMetersMin: request.DistanceMetersMin,
+		DistanceMetersMax: request.DistanceMetersMax,
+		AvgHeartRateMin:   request.AvgHeartRateMin,
+		AvgHeartRateMax:   request.AvgHeartRateMax,
 		UserId:            userid,
+		Sort:              sort,
 	}
 
 	activities, err := c.activityRepo.ListActivities(ctx, arg)
@@ -39,65 +122,395 @@ func (c *ActivityUseCase) GetActivity(ctx context.Context, request *dto.GetActiv
 		return nil, errors.Wrap(err, "failed to get activities")
 	}
 
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, err
+	}
+
 	return &activities, nil
 }
 
-var activityCalories = map[model.ActivityTypeEnum]int{
-	model.ActivityTypeEnumWalking:    4,
-	model.ActivityTypeEnumYoga:       4,
-	model.ActivityTypeEnumStretching: 4,
-	model.ActivityTypeEnumCycling:    8,
-	model.ActivityTypeEnumSwimming:   8,
-	model.ActivityTypeEnumDancing:    8,
-	model.ActivityTypeEnumHiking:     10,
-	model.ActivityTypeEnumRunning:    10,
-	model.ActivityTypeEnumHIIT:       10,
-	model.ActivityTypeEnumJumpRope:   10,
+// GetActivityPage lists activities newest first using keyset pagination on
+// (done_at, id). An empty cursor returns the first page.
+func (c *ActivityUseCase) GetActivityPage(ctx context.Context, request *dto.GetActivityRequest, userId int) ([]model.Activity, *string, error) {
+	arg := repository.ListActivitiesByCursorParams{
+		// fetch one extra row to know whether another page exists
+		Limit:             request.Limit + 1,
+		ActivityType:      request.ActivityType,
+		DoneAtFrom:        request.DoneAtFrom,
+		DoneAtTo:          request.DoneAtTo,
+		CaloriesBurnedMin: request.CaloriesBurnedMin,
+		CaloriesBurnedMax: request.CaloriesBurnedMax,
+		DistanceMetersMin: request.DistanceMetersMin,
+		DistanceMetersMax: request.DistanceMetersMax,
+		AvgHeartRateMin:   request.AvgHeartRateMin,
+		AvgHeartRateMax:   request.AvgHeartRateMax,
+		UserId:            userId,
+	}
+
+	if request.Cursor != nil && *request.Cursor != "" {
+		cursor, err := decodeCursor(*request.Cursor)
+		if err != nil {
+			return nil, nil, err
+		}
+		arg.AfterDoneAt = &cursor.DoneAt
+		arg.AfterId = &cursor.Id
+	}
+
+	activities, err := c.activityRepo.ListActivitiesByCursor(ctx, arg)
+	if err != nil {
+		return nil, nil, errors.Wrap(err, "failed to get activities")
+	}
+
+	if len(activities) <= request.Limit {
+		if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+			return nil, nil, err
+		}
+		return activities, nil, nil
+	}
+
+	activities = activities[:request.Limit]
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, nil, err
+	}
+	nextCursor := encodeCursor(activities[len(activities)-1])
+	return activities, &nextCursor, nil
+}
+
+// ExportActivities streams the activities to fn. Exercises are loaded for
+// EXPORT_BATCH_SIZE activities at a time.
+func (c *ActivityUseCase) ExportActivities(ctx context.Context, request *dto.ExportActivityRequest, userId int, fn func(model.Activity) error) error {
+	arg := repository.StreamActivitiesParams{
+		ActivityType:      request.ActivityType,
+		DoneAtFrom:        request.DoneAtFrom,
+		DoneAtTo:          request.DoneAtTo,
+		CaloriesBurnedMin: request.CaloriesBurnedMin,
+		CaloriesBurnedMax: request.CaloriesBurnedMax,
+		DistanceMetersMin: request.DistanceMetersMin,
+		DistanceMetersMax: request.DistanceMetersMax,
+		AvgHeartRateMin:   request.AvgHeartRateMin,
+		AvgHeartRateMax:   request.AvgHeartRateMax,
+		UserId:            userId,
+	}
+
+	batch := make([]model.Activity, 0, EXPORT_BATCH_SIZE)
+	flush := func() error {
+		if err := c.activityRepo.AttachExercises(ctx, batch); err != nil {
+			return err
+		}
+		for _, activity := range batch {
+			if err := fn(activity); err != nil {
+				return err
+			}
+		}
+		batch = batch[:0]
+		return nil
+	}
+
+	err := c.activityRepo.StreamActivities(ctx, arg, func(activity model.Activity) error {
+		batch = append(batch, activity)
+		if len(batch) < EXPORT_BATCH_SIZE {
+			return nil
+		}
+		return flush()
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to export activities")
+	}
+
+	return nil
+}
+
+func (c *ActivityUseCase) GetActivityStats(ctx context.Context, request *dto.GetActivityStatsRequest, userId int) ([]model.ActivityStat, error) {
+	arg := repository.ListActivityStatsParams{
+		Period:       request.Period,
+		GroupByType:  request.GroupByType,
+		UserId:       userId,
+		ActivityType: request.ActivityType,
+		DoneAtFrom:   request.DoneAtFrom,
+		DoneAtTo:     request.DoneAtTo,
+	}
+
+	stats, err := c.activityRepo.ListActivityStats(ctx, arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get activity stats")
+	}
+
+	return stats, nil
+}
+
+// GetActivityStreaks returns the daily and weekly streaks of a user.
+func (c *ActivityUseCase) GetActivityStreaks(ctx context.Context, userId int) (*model.ActivityStreaks, error) {
+	timeZone, daily, err := c.activityRepo.GetActivityStreak(ctx, repository.GetActivityStreakParams{
+		UserId:   userId,
+		Unit:     "day",
+		StepDays: 1,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get daily streak")
+	}
+
+	_, weekly, err := c.activityRepo.GetActivityStreak(ctx, repository.GetActivityStreakParams{
+		UserId:   userId,
+		Unit:     "week",
+		StepDays: 7,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get weekly streak")
+	}
+
+	return &model.ActivityStreaks{
+		TimeZone: timeZone,
+		Daily:    daily,
+		Weekly:   weekly,
+	}, nil
+}
+
+func (c *ActivityUseCase) userWeightKg(ctx context.Context, userId int) (*float64, error) {
+	weight, err := c.activityRepo.GetUserWeight(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user weight")
+	}
+
+	return weightInKg(weight), nil
+}
+
+// ResolveActivityType returns the global or custom type of the user with the
+// given name.
+func (c *ActivityUseCase) ResolveActivityType(ctx context.Context, userId int, name model.ActivityTypeEnum) (model.ActivityType, error) {
+	return c.activityTypes.Resolve(ctx, userId, name)
+}
+
+// estimateCalories also rejects types that are not available to the user.
+func (c *ActivityUseCase) estimateCalories(ctx context.Context, name model.ActivityTypeEnum, durationInMinutes int, userId int) (CalorieEstimate, error) {
+	activityType, err := c.activityTypes.Resolve(ctx, userId, name)
+	if err != nil {
+		return CalorieEstimate{}, err
+	}
+
+	weightKg, err := c.userWeightKg(ctx, userId)
+	if err != nil {
+		return CalorieEstimate{}, err
+	}
+
+	return c.calorieEstimator.Estimate(activityType, durationInMinutes, weightKg), nil
+}
+
+// activityLimitError explains a rejection by the activity limit, and wraps
+// other errors with message.
+func activityLimitError(err error, limit *int, message string) error {
+	if limit != nil && errors.Is(err, customErrors.ErrForbidden) {
+		return errors.Wrap(customErrors.ErrForbidden, fmt.Sprintf("verify your email to log more than %d activities", *limit))
+	}
+	return errors.Wrap(err, message)
 }
 
-func calculateCalories(activityType model.ActivityTypeEnum, duration int) int {
-	caloriesPerMinute := activityCalories[activityType]
-	return caloriesPerMinute * duration
+func validateHeartRate(avgHeartRate, maxHeartRate *int) error {
+	if avgHeartRate != nil && maxHeartRate != nil && *maxHeartRate < *avgHeartRate {
+		return errors.Wrap(customErrors.ErrBadRequest, "maxHeartRate must not be lower than avgHeartRate")
+	}
+	return nil
 }
 
-func (c *ActivityUseCase) CreateActivity(ctx context.Context, request *dto.CreateAndUpdateActivityRequest, userId int) (*model.Activity, error) {
+func (c *ActivityUseCase) CreateActivity(ctx context.Context, request *dto.CreateAndUpdateActivityRequest, userId int) (*model.Activity, []recordModel.PersonalRecord, error) {
+	if err := validateHeartRate(request.AvgHeartRate, request.MaxHeartRate); err != nil {
+		return nil, nil, err
+	}
+
+	limit, err := c.activityQuota.ActivityLimit(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	estimate, err := c.estimateCalories(ctx, request.ActivityType, request.DurationInMinutes, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	exercises, err := c.toExercises(ctx, request.ActivityType, request.Exercises, userId)
+	if err != nil {
+		return nil, nil, err
+	}
 
-	caloriesBurned := calculateCalories(request.ActivityType, request.DurationInMinutes)
 	arg := repository.CreateActivityParams{
-		ActivityType:      request.ActivityType,
-		DoneAt:            request.DoneAt,
-		DurationInMinutes: request.DurationInMinutes,
-		CaloriesBurned:    caloriesBurned,
-		UserId:            userId,
+		ActivityType:        request.ActivityType,
+		DoneAt:              request.DoneAt,
+		DurationInMinutes:   request.DurationInMinutes,
+		CaloriesBurned:      estimate.Calories,
+		CalorieModel:        estimate.Model,
+		MetValue:            estimate.MetValue,
+		WeightKg:            estimate.WeightKg,
+		DistanceMeters:      request.DistanceMeters,
+		ElevationGainMeters: request.ElevationGainMeters,
+		AvgHeartRate:        request.AvgHeartRate,
+		MaxHeartRate:        request.MaxHeartRate,
+		Notes:               request.Notes,
+		Exercises:           exercises,
+		UserId:              userId,
 	}
 
-	activity, err := c.activityRepo.CreateActivity(ctx, arg)
+	activity, err := c.activityRepo.CreateActivity(ctx, arg, limit)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to create activity")
+		return nil, nil, activityLimitError(err, limit, "failed to create activity")
 	}
 
-	return &activity, nil
+	return &activity, c.afterSave(ctx, activity), nil
 }
 
-func (c *ActivityUseCase) UpdateActivity(ctx context.Context, request *dto.CreateAndUpdateActivityRequest, activityId int, userId int) (*model.Activity, error) {
-	caloriesBurned := calculateCalories(request.ActivityType, request.DurationInMinutes)
-	timeNow := time.Now()
+// ImportActivities stores already validated rows in one transaction.
+func (c *ActivityUseCase) ImportActivities(ctx context.Context, requests []dto.CreateAndUpdateActivityRequest, userId int) error {
+	limit, err := c.activityQuota.ActivityLimit(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	weightKg, err := c.userWeightKg(ctx, userId)
+	if err != nil {
+		return err
+	}
+
+	args := make([]repository.CreateActivityParams, 0, len(requests))
+	var activityTypes []model.ActivityTypeEnum
+	// custom types are looked up in the database, so resolve each name once
+	resolved := make(map[model.ActivityTypeEnum]model.ActivityType)
+	for _, request := range requests {
+		activityType, ok := resolved[request.ActivityType]
+		if !ok {
+			activityType, err = c.activityTypes.Resolve(ctx, userId, request.ActivityType)
+			if err != nil {
+				return err
+			}
+			resolved[request.ActivityType] = activityType
+			activityTypes = append(activityTypes, request.ActivityType)
+		}
+
+		estimate := c.calorieEstimator.Estimate(activityType, request.DurationInMinutes, weightKg)
+		args = append(args, repository.CreateActivityParams{
+			ActivityType:      request.ActivityType,
+			DoneAt:            request.DoneAt,
+			DurationInMinutes: request.DurationInMinutes,
+			CaloriesBurned:    estimate.Calories,
+			CalorieModel:      estimate.Model,
+			MetValue:          estimate.MetValue,
+			WeightKg:          estimate.WeightKg,
+			UserId:            userId,
+		})
+	}
+
+	if err := c.activityRepo.CreateActivities(ctx, userId, args, limit); err != nil {
+		return activityLimitError(err, limit, "failed to import activities")
+	}
+
+	c.recomputeRecords(ctx, userId, activityTypes...)
+	c.evaluateAchievements(ctx, userId)
+	return nil
+}
+
+func (c *ActivityUseCase) GetActivityById(ctx context.Context, activityId int, userId int) (*model.Activity, error) {
+	activity, err := c.activityRepo.GetActivity(ctx, repository.GetAndDeleteActivityParams{
+		Id:     activityId,
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get activity")
+	}
+
+	activities := []model.Activity{activity}
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, err
+	}
+
+	return &activities[0], nil
+}
+
+// UpdateActivity applies a partial update. When ifMatch is set the update only
+// succeeds if it matches the activity's current ETag.
+func (c *ActivityUseCase) UpdateActivity(ctx context.Context, request *dto.PatchActivityRequest, activityId int, userId int, ifMatch string) (*model.Activity, []recordModel.PersonalRecord, error) {
+	if request.ActivityType.IsNull() || request.DoneAt.IsNull() || request.DurationInMinutes.IsNull() {
+		return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "activityType, doneAt and durationInMinutes cannot be null")
+	}
+
+	existing, err := c.GetActivityById(ctx, activityId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ifMatch != "" && !helper.MatchesETag(ifMatch, helper.ETag(existing.UpdatedAt)) {
+		return nil, nil, errors.Wrap(customErrors.ErrPreconditionFailed, "activity has been modified")
+	}
+
+	err = validateHeartRate(request.AvgHeartRate.Or(existing.AvgHeartRate), request.MaxHeartRate.Or(existing.MaxHeartRate))
+	if err != nil {
+		return nil, nil, err
+	}
+
 	arg := repository.PatchActivitiesParams{
-		ActivityType:      request.ActivityType,
-		DoneAt:            request.DoneAt,
-		UpdatedAt:         timeNow,
-		DurationInMinutes: request.DurationInMinutes,
-		CaloriesBurned:    caloriesBurned,
-		ActivityId:        activityId,
-		UserId:            userId,
+		ActivityType:        request.ActivityType.Value,
+		DoneAt:              request.DoneAt.Value,
+		UpdatedAt:           time.Now(),
+		DurationInMinutes:   request.DurationInMinutes.Value,
+		DistanceMeters:      request.DistanceMeters,
+		ElevationGainMeters: request.ElevationGainMeters,
+		AvgHeartRate:        request.AvgHeartRate,
+		MaxHeartRate:        request.MaxHeartRate,
+		Notes:               request.Notes,
+		ActivityId:          activityId,
+		UserId:              userId,
+	}
+	if ifMatch != "" {
+		arg.ExpectedUpdatedAt = &existing.UpdatedAt
+	}
+
+	activityType := *request.ActivityType.Or(&existing.ActivityType)
+	durationInMinutes := *request.DurationInMinutes.Or(&existing.DurationInMinutes)
+	if activityType != existing.ActivityType || durationInMinutes != existing.DurationInMinutes {
+		estimate, err := c.estimateCalories(ctx, activityType, durationInMinutes, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		arg.Recalculated = true
+		arg.CaloriesBurned = &estimate.Calories
+		arg.CalorieModel = &estimate.Model
+		arg.MetValue = estimate.MetValue
+		arg.WeightKg = estimate.WeightKg
+	}
+
+	if request.Exercises != nil {
+		exercises, err := c.toExercises(ctx, activityType, *request.Exercises, userId)
+		if err != nil {
+			return nil, nil, err
+		}
+		arg.Exercises = &exercises
+	} else if activityType != existing.ActivityType && len(existing.Exercises) > 0 {
+		if err := c.requireStrength(ctx, activityType, userId); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	activity, err := c.activityRepo.UpdateActivityRepo(ctx, arg)
 	if err != nil {
-		return nil, errors.Wrap(err, "failed to update Activity")
+		if arg.ExpectedUpdatedAt != nil && errors.Is(err, customErrors.ErrNotFound) {
+			return nil, nil, errors.Wrap(customErrors.ErrPreconditionFailed, "activity has been modified")
+		}
+		return nil, nil, errors.Wrap(err, "failed to update Activity")
+	}
+	if arg.Exercises == nil {
+		activity.Exercises = existing.Exercises
+	}
+
+	// an edit can lower a record as well as set one, so rebuild them
+	var records []recordModel.PersonalRecord
+	for _, record := range c.recomputeRecords(ctx, userId, existing.ActivityType, activity.ActivityType) {
+		if record.ActivityId != nil && *record.ActivityId == activity.ID {
+			records = append(records, record)
+		}
 	}
 
-	return activity, nil
+	c.evaluateAchievements(ctx, userId)
+
+	return activity, records, nil
 }
 
 func (c *ActivityUseCase) DeleteActivity(ctx context.Context, activityId int, userId int) error {
@@ -107,5 +520,78 @@ func (c *ActivityUseCase) DeleteActivity(ctx context.Context, activityId int, us
 		UserId:     userId,
 	}
 
-	return c.activityRepo.DeleteActivity(ctx, arg)
+	activityType, err := c.activityRepo.DeleteActivity(ctx, arg)
+	if err != nil {
+		return err
+	}
+
+	c.recomputeRecords(ctx, userId, activityType)
+	return nil
+}
+
+func (c *ActivityUseCase) GetTrash(ctx context.Context, request *dto.GetTrashRequest, userId int) ([]model.Activity, error) {
+	arg := repository.ListDeletedActivitiesParams{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		UserId: userId,
+	}
+
+	activities, err := c.activityRepo.ListDeletedActivities(ctx, arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get deleted activities")
+	}
+
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, err
+	}
+
+	return activities, nil
+}
+
+func (c *ActivityUseCase) RestoreActivity(ctx context.Context, activityId int, userId int) (*model.Activity, error) {
+	limit, err := c.activityQuota.ActivityLimit(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := repository.GetAndDeleteActivityParams{
+		Id:     activityId,
+		UserId: userId,
+	}
+
+	activity, err := c.activityRepo.RestoreActivity(ctx, arg, limit)
+	if err != nil {
+		return nil, activityLimitError(err, limit, "failed to restore activity")
+	}
+
+	c.recomputeRecords(ctx, userId, activity.ActivityType)
+
+	activities := []model.Activity{activity}
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, err
+	}
+
+	return &activities[0], nil
+}
+
+// RunTrashPurge permanently deletes activities that stayed in the trash longer
+// than retention, checking every interval until ctx is done.
+func (c *ActivityUseCase) RunTrashPurge(ctx context.Context, retention time.Duration, interval time.Duration, log *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		purged, err := c.activityRepo.PurgeDeletedActivities(ctx, time.Now().Add(-retention))
+		if err != nil {
+			log.WithError(err).Error("failed to purge activity trash")
+		} else if purged > 0 {
+			log.WithField("purged", purged).Info("purged activity trash")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
 }
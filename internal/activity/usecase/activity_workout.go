@@ -0,0 +1,96 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"strings"
+
+	"fit-byte/internal/activity/model"
+	"fit-byte/internal/activity/repository"
+	recordModel "fit-byte/internal/records/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/workout"
+
+	"github.com/pkg/errors"
+)
+
+// sportActivityTypes maps GPX track types and TCX sports to activity types.
+var sportActivityTypes = map[string]model.ActivityTypeEnum{
+	"running":  model.ActivityTypeEnumRunning,
+	"run":      model.ActivityTypeEnumRunning,
+	"cycling":  model.ActivityTypeEnumCycling,
+	"biking":   model.ActivityTypeEnumCycling,
+	"bike":     model.ActivityTypeEnumCycling,
+	"ride":     model.ActivityTypeEnumCycling,
+	"hiking":   model.ActivityTypeEnumHiking,
+	"hike":     model.ActivityTypeEnumHiking,
+	"walking":  model.ActivityTypeEnumWalking,
+	"walk":     model.ActivityTypeEnumWalking,
+	"swimming": model.ActivityTypeEnumSwimming,
+	"swim":     model.ActivityTypeEnumSwimming,
+}
+
+func activityTypeForSport(sport string) (model.ActivityTypeEnum, bool) {
+	activityType, ok := sportActivityTypes[strings.ToLower(strings.TrimSpace(sport))]
+	return activityType, ok
+}
+
+// CreateActivityFromWorkout stores a parsed GPS workout. activityType overrides
+// the sport recorded in the file when it is set.
+func (c *ActivityUseCase) CreateActivityFromWorkout(ctx context.Context, w *workout.Workout, activityType *model.ActivityTypeEnum, userId int) (*model.Activity, []recordModel.PersonalRecord, error) {
+	if activityType == nil {
+		mapped, ok := activityTypeForSport(w.Sport)
+		if !ok {
+			return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "unknown sport, activityType is required")
+		}
+		activityType = &mapped
+	}
+
+	durationInMinutes := int(math.Round(w.ElapsedTime.Minutes()))
+	if durationInMinutes < 1 {
+		return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "workout is shorter than a minute")
+	}
+
+	limit, err := c.activityQuota.ActivityLimit(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	estimate, err := c.estimateCalories(ctx, *activityType, durationInMinutes, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	arg := repository.CreateActivityParams{
+		ActivityType:        *activityType,
+		DoneAt:              w.StartTime,
+		DurationInMinutes:   durationInMinutes,
+		CaloriesBurned:      estimate.Calories,
+		CalorieModel:        estimate.Model,
+		MetValue:            estimate.MetValue,
+		WeightKg:            estimate.WeightKg,
+		DistanceMeters:      roundMeters(w.DistanceMeters),
+		ElevationGainMeters: roundMeters(w.ElevationGainMeters),
+		UserId:              userId,
+	}
+
+	activity, err := c.activityRepo.CreateActivity(ctx, arg, limit)
+	if err != nil {
+		return nil, nil, activityLimitError(err, limit, "failed to create activity")
+	}
+
+	return &activity, c.afterSave(ctx, activity), nil
+}
+
+// roundMeters keeps nil for values the file did not measure. Zero is dropped
+// as well, like the API, which only accepts positive distances.
+func roundMeters(meters *float64) *float64 {
+	if meters == nil {
+		return nil
+	}
+	rounded := math.Round(*meters*100) / 100
+	if rounded <= 0 {
+		return nil
+	}
+	return &rounded
+}
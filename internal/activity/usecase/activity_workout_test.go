@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"testing"
+
+	"fit-byte/internal/activity/model"
+)
+
+func TestActivityTypeForSport(t *testing.T) {
+	tests := []struct {
+		sport string
+		want  model.ActivityTypeEnum
+		ok    bool
+	}{
+		{sport: "running", want: model.ActivityTypeEnumRunning, ok: true},
+		{sport: "Running", want: model.ActivityTypeEnumRunning, ok: true},
+		{sport: "Biking", want: model.ActivityTypeEnumCycling, ok: true},
+		{sport: " hike ", want: model.ActivityTypeEnumHiking, ok: true},
+		{sport: "Other", ok: false},
+		{sport: "", ok: false},
+	}
+
+	for _, tt := range tests {
+		got, ok := activityTypeForSport(tt.sport)
+		if got != tt.want || ok != tt.ok {
+			t.Errorf("activityTypeForSport(%q) = %q, %v, want %q, %v", tt.sport, got, ok, tt.want, tt.ok)
+		}
+	}
+}
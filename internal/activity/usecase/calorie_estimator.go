@@ -0,0 +1,89 @@
+package usecase
+
+import (
+	"math"
+
+	"fit-byte/internal/activity/model"
+)
+
+const (
+	CalorieModelFlat = "FLAT"
+	CalorieModelMET  = "MET"
+
+	lbsToKg = 0.45359237
+
+	// referenceWeightKg is used for types without a calories-per-minute value
+	// when the user's weight is unknown.
+	referenceWeightKg = 70
+)
+
+type CalorieEstimate struct {
+	Calories int
+	Model    string
+	MetValue *float64
+	WeightKg *float64
+}
+
+// CalorieEstimator turns an activity into burned calories. weightKg is nil
+// when the user has not filled in their profile.
+type CalorieEstimator interface {
+	Estimate(activityType model.ActivityType, durationInMinutes int, weightKg *float64) CalorieEstimate
+}
+
+// FlatCalorieEstimator uses the calories-per-minute value of the activity
+// type. Types that only have a MET value are estimated for a reference weight.
+type FlatCalorieEstimator struct{}
+
+func (FlatCalorieEstimator) Estimate(activityType model.ActivityType, durationInMinutes int, _ *float64) CalorieEstimate {
+	var calories int
+	switch {
+	case activityType.CaloriesPerMinute != nil:
+		calories = *activityType.CaloriesPerMinute * durationInMinutes
+	case activityType.MetValue != nil:
+		calories = int(math.Round(*activityType.MetValue * referenceWeightKg * float64(durationInMinutes) / 60))
+	}
+
+	return CalorieEstimate{
+		Calories: calories,
+		Model:    CalorieModelFlat,
+	}
+}
+
+// METCalorieEstimator computes kcal = MET * weight (kg) * hours and defers to
+// Fallback when the user's weight or the MET value of the type is unknown.
+type METCalorieEstimator struct {
+	Fallback CalorieEstimator
+}
+
+func NewMETCalorieEstimator(fallback CalorieEstimator) *METCalorieEstimator {
+	return &METCalorieEstimator{
+		Fallback: fallback,
+	}
+}
+
+func (e *METCalorieEstimator) Estimate(activityType model.ActivityType, durationInMinutes int, weightKg *float64) CalorieEstimate {
+	if activityType.MetValue == nil || weightKg == nil || *weightKg <= 0 {
+		return e.Fallback.Estimate(activityType, durationInMinutes, weightKg)
+	}
+
+	met := *activityType.MetValue
+	calories := met * *weightKg * float64(durationInMinutes) / 60
+	return CalorieEstimate{
+		Calories: int(math.Round(calories)),
+		Model:    CalorieModelMET,
+		MetValue: &met,
+		WeightKg: weightKg,
+	}
+}
+
+func weightInKg(weight model.UserWeight) *float64 {
+	if weight.Weight == nil {
+		return nil
+	}
+
+	kg := float64(*weight.Weight)
+	if weight.WeightUnit != nil && *weight.WeightUnit == "LBS" {
+		kg = math.Round(kg*lbsToKg*100) / 100
+	}
+	return &kg
+}
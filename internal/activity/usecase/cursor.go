@@ -0,0 +1,52 @@
+package usecase
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"fit-byte/internal/activity/model"
+	customErrors "fit-byte/pkg/custom-errors"
+
+	"github.com/pkg/errors"
+)
+
+// activityCursor points at the last activity of a page, ordered by (done_at, id).
+type activityCursor struct {
+	DoneAt time.Time
+	Id     int
+}
+
+func encodeCursor(activity model.Activity) string {
+	raw := fmt.Sprintf("%d:%d", activity.DoneAt.UnixMicro(), activity.ID)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(cursor string) (*activityCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid cursor")
+	}
+
+	doneAt, id, found := strings.Cut(string(raw), ":")
+	if !found {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid cursor")
+	}
+
+	micros, err := strconv.ParseInt(doneAt, 10, 64)
+	if err != nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid cursor")
+	}
+
+	activityId, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid cursor")
+	}
+
+	return &activityCursor{
+		DoneAt: time.UnixMicro(micros).UTC(),
+		Id:     activityId,
+	}, nil
+}
@@ -0,0 +1,26 @@
+package config
+
+import (
+	"context"
+	"fit-byte/db"
+	"os"
+
+	activityRepository "fit-byte/internal/activity/repository"
+	activityUsecase "fit-byte/internal/activity/usecase"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewActivityTypes loads the global activity type cache. Calorie estimation
+// depends on it, so it has to be ready before any request is served.
+func NewActivityTypes(pg *db.Postgres, log *logrus.Logger) *activityUsecase.ActivityTypeUseCase {
+	activityTypeRepo := activityRepository.NewActivityTypeRepository(pg.Pool)
+	activityTypes := activityUsecase.NewActivityTypeUseCase(*activityTypeRepo)
+
+	if err := activityTypes.Load(context.Background()); err != nil {
+		log.Fatal("unable to load activity types", err.Error())
+		os.Exit(1)
+	}
+
+	return activityTypes
+}
@@ -1,9 +1,11 @@
 package config
 
 import (
+	"context"
 	"fit-byte/db"
 	file_handler "fit-byte/internal/file/handler"
 	file_usecase "fit-byte/internal/file/usecase"
+	idempotency_repository "fit-byte/internal/idempotency/repository"
 	custom_middleware "fit-byte/internal/middleware"
 	"fit-byte/internal/routes"
 	user_handler "fit-byte/internal/users/handler"
@@ -12,9 +14,21 @@ import (
 	"fit-byte/pkg/dotenv"
 	"time"
 
+	achievementHandler "fit-byte/internal/achievements/handler"
+	achievementRepository "fit-byte/internal/achievements/repository"
+	achievementUsecase "fit-byte/internal/achievements/usecase"
 	activityHandler "fit-byte/internal/activity/handler"
 	activityRepository "fit-byte/internal/activity/repository"
 	activityUsecase "fit-byte/internal/activity/usecase"
+	goalHandler "fit-byte/internal/goals/handler"
+	goalRepository "fit-byte/internal/goals/repository"
+	goalUsecase "fit-byte/internal/goals/usecase"
+	plannedWorkoutHandler "fit-byte/internal/plannedworkouts/handler"
+	plannedWorkoutRepository "fit-byte/internal/plannedworkouts/repository"
+	plannedWorkoutUsecase "fit-byte/internal/plannedworkouts/usecase"
+	recordHandler "fit-byte/internal/records/handler"
+	recordRepository "fit-byte/internal/records/repository"
+	recordUsecase "fit-byte/internal/records/usecase"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 	"github.com/go-playground/validator/v10"
@@ -23,43 +37,112 @@ import (
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	DEFAULT_TRASH_RETENTION = 30 * 24 * time.Hour
+	TRASH_PURGE_INTERVAL    = time.Hour
+
+	ACTIVITY_TYPE_REFRESH_INTERVAL = 5 * time.Minute
+)
+
 type BootstrapConfig struct {
-	Env        *dotenv.Env
-	App        *echo.Echo
-	DB         *db.Postgres
-	Log        *logrus.Logger
-	Validator  *validator.Validate
-	S3Uploader *manager.Uploader
+	Env           *dotenv.Env
+	App           *echo.Echo
+	DB            *db.Postgres
+	Log           *logrus.Logger
+	Validator     *validator.Validate
+	ActivityTypes *activityUsecase.ActivityTypeUseCase
+	S3Uploader    *manager.Uploader
 }
 
 func Bootstrap(config *BootstrapConfig) {
 	//activity
 	activityRepo := activityRepository.NewActivityRepository(config.DB.Pool)
-	activityUsecase := activityUsecase.NewActivityUseCase(*activityRepo)
+
+	//unverified accounts
+	userRepo := user_repository.NewUserRepo(config.DB.Pool)
+	verificationPolicy := user_usecase.NewVerificationPolicy(userRepo, config.Env)
+
+	//records
+	recordRepo := recordRepository.NewRecordRepository(config.DB.Pool)
+	recordUsecase := recordUsecase.NewRecordUseCase(*recordRepo, *activityRepo)
+	recordHandler := recordHandler.NewRecordHandler(*recordUsecase, config.Validator)
+
+	//achievements
+	achievementRepo := achievementRepository.NewAchievementRepository(config.DB.Pool)
+	achievementUsecase := achievementUsecase.NewAchievementUseCase(*achievementRepo, *activityRepo)
+	achievementHandler := achievementHandler.NewAchievementHandler(*achievementUsecase)
+
+	activityTypeHandler := activityHandler.NewActivityTypeHandler(config.ActivityTypes, config.Validator)
+	activityUsecase := activityUsecase.NewActivityUseCase(*activityRepo, config.ActivityTypes, recordUsecase, achievementUsecase, verificationPolicy, config.Log)
 	activityHandler := activityHandler.NewActivityHandler(*activityUsecase, config.Validator)
+	go config.ActivityTypes.RunRefresh(context.Background(), ACTIVITY_TYPE_REFRESH_INTERVAL, config.Log)
+
+	trashRetention, err := time.ParseDuration(config.Env.TRASH_RETENTION)
+	if err != nil || trashRetention <= 0 {
+		trashRetention = DEFAULT_TRASH_RETENTION
+	}
+	go activityUsecase.RunTrashPurge(context.Background(), trashRetention, TRASH_PURGE_INTERVAL, config.Log)
+
+	//planned workouts
+	plannedWorkoutRepo := plannedWorkoutRepository.NewPlannedWorkoutRepository(config.DB.Pool)
+	plannedWorkoutUsecase := plannedWorkoutUsecase.NewPlannedWorkoutUseCase(*plannedWorkoutRepo, *activityUsecase, config.Log)
+	plannedWorkoutHandler := plannedWorkoutHandler.NewPlannedWorkoutHandler(*plannedWorkoutUsecase, config.Validator)
+
+	//goals
+	goalRepo := goalRepository.NewGoalRepository(config.DB.Pool)
+	goalUsecase := goalUsecase.NewGoalUseCase(*goalRepo, config.ActivityTypes)
+	goalHandler := goalHandler.NewGoalHandler(*goalUsecase, config.Validator)
 
 	// * Middleware
+	// login throttling is keyed by ctx.RealIP(), which must not trust client headers
+	config.App.IPExtractor = NewIPExtractor(config.Env, config.Log)
 	config.App.Use(middleware.TimeoutWithConfig(middleware.TimeoutConfig{
-		Skipper:      middleware.DefaultSkipper,
+		// the timeout handler buffers the whole response, which defeats streaming
+		Skipper: func(c echo.Context) bool {
+			return c.Path() == "/v1/activity/export"
+		},
 		ErrorMessage: "Timeout",
 		Timeout:      30 * time.Second,
 	}))
 
-	userRepo := user_repository.NewUserRepo(config.DB.Pool)
-	userUsecase := user_usecase.NewUserUsecase(userRepo, config.Env)
+	refreshTokenRepo := user_repository.NewRefreshTokenRepo(config.DB.Pool)
+	revokedTokenRepo := user_repository.NewRevokedTokenRepo(config.DB.Pool)
+	tokenRevocations := user_usecase.NewTokenRevocations(revokedTokenRepo, userRepo)
+	if err := tokenRevocations.Load(context.Background()); err != nil {
+		config.Log.Fatal("unable to load revoked tokens", err.Error())
+	}
+	go tokenRevocations.RunRefresh(context.Background(), user_usecase.REVOKED_TOKEN_REFRESH_INTERVAL, config.Log)
+	passwordResetRepo := user_repository.NewPasswordResetRepo(config.DB.Pool)
+	emailVerificationRepo := user_repository.NewEmailVerificationRepo(config.DB.Pool)
+	loginThrottle := user_usecase.NewLoginThrottle(user_repository.NewLoginFailureRepo(config.DB.Pool), config.Env)
+	twoFactorRepo := user_repository.NewTwoFactorRepo(config.DB.Pool)
+	loginChallengeRepo := user_repository.NewLoginChallengeRepo(config.DB.Pool)
+	secretBox := NewSecretBox(config.Env, config.Log)
+	mailer := NewMailer(config.Env, config.Log)
+	userUsecase := user_usecase.NewUserUsecase(userRepo, refreshTokenRepo, passwordResetRepo, emailVerificationRepo, twoFactorRepo, loginChallengeRepo, tokenRevocations, loginThrottle, secretBox, mailer, config.Env, config.Log)
 	userHandler := user_handler.NewUserHandler(config.Validator, userUsecase)
 
 	fileUsecase := file_usecase.NewFileUseCase(config.S3Uploader, config.Env)
 	fileHandler := file_handler.NewFileHandler(fileUsecase, config.Log)
 
-	authMiddleware := custom_middleware.NewAuthMiddleware(config.Env)
+	authMiddleware := custom_middleware.NewAuthMiddleware(config.Env, tokenRevocations)
+	verificationMiddleware := custom_middleware.NewVerificationMiddleware(verificationPolicy)
+	idempotencyRepo := idempotency_repository.NewIdempotencyRepository(config.DB.Pool)
+	idempotencyMiddleware := custom_middleware.NewIdempotencyMiddleware(idempotencyRepo, config.Env, config.Log)
 	routes := routes.RouteConfig{
-		App:             config.App,
-		S3Uploader:      config.S3Uploader,
-		ActivityHandler: activityHandler,
-		UserHandler:     userHandler,
-		FileHandler:     fileHandler,
-		Middleware:      authMiddleware,
+		App:                   config.App,
+		S3Uploader:            config.S3Uploader,
+		ActivityHandler:       activityHandler,
+		ActivityTypeHandler:   activityTypeHandler,
+		GoalHandler:           goalHandler,
+		RecordHandler:         recordHandler,
+		AchievementHandler:    achievementHandler,
+		PlannedWorkoutHandler: plannedWorkoutHandler,
+		UserHandler:           userHandler,
+		FileHandler:           fileHandler,
+		Middleware:            authMiddleware,
+		Idempotency:           idempotencyMiddleware,
+		Verification:          verificationMiddleware,
 	}
 
 	routes.SetupRoutes()
@@ -0,0 +1,31 @@
+package config
+
+import (
+	"net"
+	"strings"
+
+	"fit-byte/pkg/dotenv"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// NewIPExtractor decides where ctx.RealIP() comes from. Without
+// TRUSTED_PROXIES the peer address is used, because any client can send an
+// X-Forwarded-For header. With it, X-Forwarded-For is only followed through
+// the listed CIDR ranges.
+func NewIPExtractor(env *dotenv.Env, log *logrus.Logger) echo.IPExtractor {
+	if env.TRUSTED_PROXIES == "" {
+		return echo.ExtractIPDirect()
+	}
+
+	var options []echo.TrustOption
+	for _, cidr := range strings.Split(env.TRUSTED_PROXIES, ",") {
+		_, ipRange, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			log.Fatal("invalid TRUSTED_PROXIES", err.Error())
+		}
+		options = append(options, echo.TrustIPRange(ipRange))
+	}
+	return echo.ExtractIPFromXFFHeader(options...)
+}
@@ -0,0 +1,22 @@
+package config
+
+import (
+	"fit-byte/pkg/dotenv"
+	"fit-byte/pkg/mailer"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewMailer writes emails to MAIL_OUTBOX_DIR when it is set and to the log
+// otherwise.
+func NewMailer(env *dotenv.Env, log *logrus.Logger) mailer.Mailer {
+	if env.MAIL_OUTBOX_DIR == "" {
+		return mailer.NewLogMailer(log)
+	}
+
+	outbox, err := mailer.NewOutboxMailer(env.MAIL_OUTBOX_DIR)
+	if err != nil {
+		log.Fatal("unable to create mail outbox", err.Error())
+	}
+	return outbox
+}
@@ -0,0 +1,25 @@
+package config
+
+import (
+	"fit-byte/pkg/dotenv"
+	"fit-byte/pkg/secretbox"
+
+	"github.com/sirupsen/logrus"
+)
+
+// NewSecretBox encrypts stored secrets with TOTP_ENCRYPTION_KEY. Without it
+// the server still starts and returns nil, and the two-factor endpoints fail
+// until the key is set; a fallback would tie the stored secrets to another
+// setting.
+func NewSecretBox(env *dotenv.Env, log *logrus.Logger) *secretbox.Box {
+	if env.TOTP_ENCRYPTION_KEY == "" {
+		log.Warn("TOTP_ENCRYPTION_KEY is not set, two-factor authentication is unavailable")
+		return nil
+	}
+
+	box, err := secretbox.New(env.TOTP_ENCRYPTION_KEY)
+	if err != nil {
+		log.Fatal("unable to create secret box", err.Error())
+	}
+	return box
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/go-playground/validator/v10"
 
 	"fit-byte/internal/activity/model"
+	"fit-byte/pkg/helper"
 	"time"
 )
 
@@ -15,29 +16,36 @@ func NewValidator() *validator.Validate {
 	validate.RegisterValidation("activity_type", activityTypeValidator)
 	validate.RegisterValidation("time_validator", timeValidator)
 	validate.RegisterValidation("is_uri", uriValidator)
+	validate.RegisterValidation("activity_sort", activitySortValidator)
+	validate.RegisterCustomTypeFunc(helper.NullableValue,
+		helper.Nullable[int]{},
+		helper.Nullable[float64]{},
+		helper.Nullable[string]{},
+		helper.Nullable[time.Time]{},
+		helper.Nullable[model.ActivityTypeEnum]{},
+		helper.Nullable[model.ActivityCategoryEnum]{},
+	)
 	return validate
 }
 
-var validActivityTypes = map[model.ActivityTypeEnum]struct{}{
-	model.ActivityTypeEnumWalking:    {},
-	model.ActivityTypeEnumYoga:       {},
-	model.ActivityTypeEnumStretching: {},
-	model.ActivityTypeEnumCycling:    {},
-	model.ActivityTypeEnumSwimming:   {},
-	model.ActivityTypeEnumDancing:    {},
-	model.ActivityTypeEnumHiking:     {},
-	model.ActivityTypeEnumRunning:    {},
-	model.ActivityTypeEnumHIIT:       {},
-	model.ActivityTypeEnumJumpRope:   {},
-}
-
+// activityTypeValidator only checks the shape of the name. Whether the type is
+// available to the requesting user is checked by ActivityTypeUseCase.Resolve.
 func activityTypeValidator(fl validator.FieldLevel) bool {
 	activity, ok := fl.Field().Interface().(model.ActivityTypeEnum)
 	if !ok {
 		return false
 	}
-	_, exists := validActivityTypes[activity]
-	return exists
+	name := strings.TrimSpace(string(activity))
+	return len(name) >= 2 && len(name) <= 50
+}
+
+func activitySortValidator(fl validator.FieldLevel) bool {
+	sort, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+	_, valid := model.ParseActivitySort(sort)
+	return valid
 }
 
 func timeValidator(fl validator.FieldLevel) bool {
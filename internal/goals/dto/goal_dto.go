@@ -0,0 +1,48 @@
+package dto
+
+import (
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/goals/model"
+	"fit-byte/pkg/helper"
+)
+
+type GoalResponse struct {
+	GoalId          string                              `json:"goalId"`
+	Metric          model.GoalMetricEnum                `json:"metric"`
+	Period          model.GoalPeriodEnum                `json:"period"`
+	Target          int                                 `json:"target"`
+	ActivityType    *activityModel.ActivityTypeEnum     `json:"activityType"`
+	Category        *activityModel.ActivityCategoryEnum `json:"category"`
+	PeriodStart     string                              `json:"periodStart"`
+	PeriodEnd       string                              `json:"periodEnd"`
+	Current         int                                 `json:"current"`
+	PercentComplete float64                             `json:"percentComplete"`
+	Completed       bool                                `json:"completed"`
+	CreatedAt       string                              `json:"createdAt"`
+	UpdatedAt       string                              `json:"updatedAt"`
+}
+
+type CreateGoalRequest struct {
+	Metric       model.GoalMetricEnum                `json:"metric" validate:"required,oneof=DURATION CALORIES SESSIONS"`
+	Period       model.GoalPeriodEnum                `json:"period" validate:"required,oneof=WEEK MONTH"`
+	Target       int                                 `json:"target" validate:"required,min=1,max=100000"`
+	ActivityType *activityModel.ActivityTypeEnum     `json:"activityType" validate:"omitempty,activity_type"`
+	Category     *activityModel.ActivityCategoryEnum `json:"category" validate:"omitempty,oneof=CARDIO STRENGTH FLEXIBILITY"`
+}
+
+// UpdateGoalRequest only changes the fields present in the body. Sending a null
+// activityType or category makes the goal count every activity type.
+type UpdateGoalRequest struct {
+	Metric       *model.GoalMetricEnum                               `json:"metric" validate:"omitempty,oneof=DURATION CALORIES SESSIONS"`
+	Period       *model.GoalPeriodEnum                               `json:"period" validate:"omitempty,oneof=WEEK MONTH"`
+	Target       *int                                                `json:"target" validate:"omitempty,min=1,max=100000"`
+	ActivityType helper.Nullable[activityModel.ActivityTypeEnum]     `json:"activityType" validate:"omitempty,activity_type"`
+	Category     helper.Nullable[activityModel.ActivityCategoryEnum] `json:"category" validate:"omitempty,oneof=CARDIO STRENGTH FLEXIBILITY"`
+}
+
+type GoalSuggestionResponse struct {
+	Metric       model.GoalMetricEnum            `json:"metric"`
+	Period       model.GoalPeriodEnum            `json:"period"`
+	Target       int                             `json:"target"`
+	ActivityType *activityModel.ActivityTypeEnum `json:"activityType"`
+}
@@ -0,0 +1,146 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+
+	"fit-byte/internal/goals/dto"
+	"fit-byte/internal/goals/model/converter"
+	"fit-byte/internal/goals/usecase"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+type GoalHandler struct {
+	UseCase  usecase.GoalUseCase
+	Validate *validator.Validate
+}
+
+func NewGoalHandler(useCase usecase.GoalUseCase, validate *validator.Validate) *GoalHandler {
+	return &GoalHandler{
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func (c *GoalHandler) GetGoals(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	goals, err := c.UseCase.GetGoals(ctx.Request().Context(), userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToGoalResponseList(goals)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *GoalHandler) GetGoalSuggestions(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	goals, err := c.UseCase.SuggestGoals(ctx.Request().Context(), userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToGoalSuggestionResponseList(goals)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *GoalHandler) CreateGoal(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.CreateGoalRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	goal, err := c.UseCase.CreateGoal(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToGoalResponse(*goal)
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (c *GoalHandler) GetGoalById(ctx echo.Context) error {
+	goalId, err := strconv.Atoi(ctx.Param("goalId"))
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "goal id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	goal, err := c.UseCase.GetGoalById(ctx.Request().Context(), goalId, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToGoalResponse(*goal)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *GoalHandler) UpdateGoal(ctx echo.Context) error {
+	goalId, err := strconv.Atoi(ctx.Param("goalId"))
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "goal id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	var request = new(dto.UpdateGoalRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	goal, err := c.UseCase.UpdateGoal(ctx.Request().Context(), request, goalId, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToGoalResponse(*goal)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *GoalHandler) DeleteGoal(ctx echo.Context) error {
+	goalId, err := strconv.Atoi(ctx.Param("goalId"))
+	if err != nil {
+		err = errors.Wrap(customErrors.ErrNotFound, "goal id required")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	err = c.UseCase.DeleteGoal(ctx.Request().Context(), goalId, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "deleted",
+	})
+}
@@ -0,0 +1,58 @@
+package converter
+
+import (
+	"fit-byte/internal/goals/dto"
+	"fit-byte/internal/goals/model"
+	"fit-byte/pkg/helper"
+	"math"
+	"strconv"
+)
+
+func ToGoalResponse(goal model.GoalProgress) dto.GoalResponse {
+	var percentComplete float64
+	if goal.Target > 0 {
+		percentComplete = math.Round(float64(goal.Current)/float64(goal.Target)*1000) / 10
+	}
+
+	return dto.GoalResponse{
+		GoalId:          strconv.Itoa(goal.ID),
+		Metric:          goal.Metric,
+		Period:          goal.Period,
+		Target:          goal.Target,
+		ActivityType:    goal.ActivityType,
+		Category:        goal.Category,
+		PeriodStart:     helper.FormatTimeToUTC(goal.PeriodStart),
+		PeriodEnd:       helper.FormatTimeToUTC(goal.PeriodEnd),
+		Current:         goal.Current,
+		PercentComplete: percentComplete,
+		Completed:       goal.Current >= goal.Target,
+		CreatedAt:       helper.FormatTimeToUTC(goal.CreatedAt),
+		UpdatedAt:       helper.FormatTimeToUTC(goal.UpdatedAt),
+	}
+}
+
+func ToGoalResponseList(goals []model.GoalProgress) []dto.GoalResponse {
+	// Ensure we always return an empty slice, not nil
+	if goals == nil {
+		return []dto.GoalResponse{}
+	}
+
+	responses := make([]dto.GoalResponse, len(goals))
+	for i, goal := range goals {
+		responses[i] = ToGoalResponse(goal)
+	}
+	return responses
+}
+
+func ToGoalSuggestionResponseList(goals []model.Goal) []dto.GoalSuggestionResponse {
+	responses := make([]dto.GoalSuggestionResponse, len(goals))
+	for i, goal := range goals {
+		responses[i] = dto.GoalSuggestionResponse{
+			Metric:       goal.Metric,
+			Period:       goal.Period,
+			Target:       goal.Target,
+			ActivityType: goal.ActivityType,
+		}
+	}
+	return responses
+}
@@ -0,0 +1,67 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	activityModel "fit-byte/internal/activity/model"
+)
+
+type GoalMetricEnum string
+
+const (
+	GoalMetricEnumDuration GoalMetricEnum = "DURATION"
+	GoalMetricEnumCalories GoalMetricEnum = "CALORIES"
+	GoalMetricEnumSessions GoalMetricEnum = "SESSIONS"
+)
+
+func (e *GoalMetricEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GoalMetricEnum(s)
+	case string:
+		*e = GoalMetricEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GoalMetricEnum: %T", src)
+	}
+	return nil
+}
+
+type GoalPeriodEnum string
+
+const (
+	GoalPeriodEnumWeek  GoalPeriodEnum = "WEEK"
+	GoalPeriodEnumMonth GoalPeriodEnum = "MONTH"
+)
+
+func (e *GoalPeriodEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = GoalPeriodEnum(s)
+	case string:
+		*e = GoalPeriodEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for GoalPeriodEnum: %T", src)
+	}
+	return nil
+}
+
+type Goal struct {
+	ID           int
+	UserId       int
+	Metric       GoalMetricEnum
+	Period       GoalPeriodEnum
+	Target       int
+	ActivityType *activityModel.ActivityTypeEnum
+	Category     *activityModel.ActivityCategoryEnum
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+// GoalProgress is a goal with what the user achieved in the current period.
+type GoalProgress struct {
+	Goal
+	PeriodStart time.Time
+	PeriodEnd   time.Time
+	Current     int
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"context"
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/goals/model"
+	"fit-byte/pkg/helper"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type GoalRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewGoalRepository(pool *pgxpool.Pool) *GoalRepository {
+	return &GoalRepository{pool: pool}
+}
+
+// selectGoalProgress joins every goal with the activities done in its current
+// period. Periods are calendar weeks (starting Monday) and months in the
+// user's time zone, like streaks. A category matches the global type or the
+// user's own custom type with the activity's name.
+const selectGoalProgress = `
+SELECT
+  g.id,
+  g.user_id,
+  g.metric,
+  g.period,
+  g.target,
+  g.activity_type,
+  g.category,
+  g.created_at,
+  g.updated_at,
+  p.period_start,
+  p.period_end,
+  COALESCE(a.current, 0)
+FROM goals g
+JOIN users u ON (u.id = g.user_id)
+CROSS JOIN LATERAL (
+  SELECT
+    s.local_start AT TIME ZONE u.time_zone AS period_start,
+    (s.local_start + ('1 ' || lower(g.period::text))::interval) AT TIME ZONE u.time_zone AS period_end
+  FROM (
+    SELECT date_trunc(lower(g.period::text), NOW() AT TIME ZONE u.time_zone) AS local_start
+  ) s
+) p
+LEFT JOIN LATERAL (
+  SELECT
+    CASE g.metric
+      WHEN 'DURATION' THEN SUM(act.duration_in_minutes)
+      WHEN 'CALORIES' THEN SUM(act.calories_burned)
+      ELSE COUNT(*)
+    END AS current
+  FROM activities act
+  WHERE act.user_id = g.user_id
+    AND act.deleted_at IS NULL
+    AND (g.activity_type IS NULL OR act.activity_type = g.activity_type)
+    AND (g.category IS NULL OR EXISTS (
+      SELECT 1 FROM activity_types t
+      WHERE t.name = act.activity_type
+        AND (t.user_id IS NULL OR t.user_id = g.user_id)
+        AND t.category = g.category
+    ))
+    AND act.done_at >= p.period_start
+    AND act.done_at < p.period_end
+) a ON true
+`
+
+func scanGoalProgress(row pgx.Row) (model.GoalProgress, error) {
+	var i model.GoalProgress
+	err := row.Scan(
+		&i.ID,
+		&i.UserId,
+		&i.Metric,
+		&i.Period,
+		&i.Target,
+		&i.ActivityType,
+		&i.Category,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.PeriodStart,
+		&i.PeriodEnd,
+		&i.Current,
+	)
+	return i, err
+}
+
+const createGoal = `-- name: CreateGoal :one
+INSERT INTO goals (
+  metric,
+  period,
+  target,
+  activity_type,
+  category,
+  user_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6
+) RETURNING id
+`
+
+type CreateGoalParams struct {
+	Metric       model.GoalMetricEnum
+	Period       model.GoalPeriodEnum
+	Target       int
+	ActivityType *activityModel.ActivityTypeEnum
+	Category     *activityModel.ActivityCategoryEnum
+	UserId       int
+}
+
+func (r *GoalRepository) CreateGoal(ctx context.Context, arg CreateGoalParams) (int, error) {
+	row := r.pool.QueryRow(ctx, createGoal,
+		arg.Metric,
+		arg.Period,
+		arg.Target,
+		arg.ActivityType,
+		arg.Category,
+		arg.UserId,
+	)
+	var id int
+	err := row.Scan(&id)
+	return id, err
+}
+
+const listGoals = `-- name: ListGoals :many
+` + selectGoalProgress + `
+WHERE (g.user_id = $1::bigint)
+ORDER BY g.id
+`
+
+func (r *GoalRepository) ListGoals(ctx context.Context, userId int) ([]model.GoalProgress, error) {
+	rows, err := r.pool.Query(ctx, listGoals, userId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.GoalProgress
+	for rows.Next() {
+		i, err := scanGoalProgress(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getGoal = `-- name: GetGoal :one
+` + selectGoalProgress + `
+WHERE (g.id = $1::bigint)
+  AND (g.user_id = $2::bigint)
+LIMIT 1
+`
+
+type GetAndDeleteGoalParams struct {
+	Id     int
+	UserId int
+}
+
+func (r *GoalRepository) GetGoal(ctx context.Context, arg GetAndDeleteGoalParams) (model.GoalProgress, error) {
+	row := r.pool.QueryRow(ctx, getGoal, arg.Id, arg.UserId)
+	return scanGoalProgress(row)
+}
+
+const queryUpdateGoal = `
+	UPDATE goals
+	SET
+		metric = COALESCE(@metric::enum_goal_metrics, metric),
+		period = COALESCE(@period::enum_goal_periods, period),
+		target = COALESCE(@target::INT, target),
+		activity_type = CASE WHEN @activityTypeSet::BOOL THEN @activityType::varchar ELSE activity_type END,
+		category = CASE WHEN @categorySet::BOOL THEN @category::enum_activity_categories ELSE category END
+	WHERE id = @goalId
+		AND user_id = @userId
+	RETURNING id
+`
+
+type UpdateGoalParams struct {
+	Metric       *model.GoalMetricEnum
+	Period       *model.GoalPeriodEnum
+	Target       *int
+	ActivityType helper.Nullable[activityModel.ActivityTypeEnum]
+	Category     helper.Nullable[activityModel.ActivityCategoryEnum]
+	GoalId       int
+	UserId       int
+}
+
+// UpdateGoal only changes the fields that are set. It returns pgx.ErrNoRows
+// when the goal does not exist.
+func (r *GoalRepository) UpdateGoal(ctx context.Context, arg UpdateGoalParams) error {
+	args := pgx.NamedArgs{
+		"metric":          arg.Metric,
+		"period":          arg.Period,
+		"target":          arg.Target,
+		"activityTypeSet": arg.ActivityType.Set,
+		"activityType":    arg.ActivityType.Value,
+		"categorySet":     arg.Category.Set,
+		"category":        arg.Category.Value,
+		"goalId":          arg.GoalId,
+		"userId":          arg.UserId,
+	}
+
+	var id int
+	err := r.pool.QueryRow(ctx, queryUpdateGoal, args).Scan(&id)
+	return err
+}
+
+const deleteGoal = `-- name: DeleteGoal :execrows
+DELETE FROM goals
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+`
+
+// DeleteGoal returns pgx.ErrNoRows when the goal does not exist.
+func (r *GoalRepository) DeleteGoal(ctx context.Context, arg GetAndDeleteGoalParams) error {
+	tag, err := r.pool.Exec(ctx, deleteGoal, arg.Id, arg.UserId)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute delete statements")
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+const getUserPreference = `-- name: GetUserPreference :one
+SELECT preference FROM users
+WHERE (id = $1::bigint)
+LIMIT 1
+`
+
+func (r *GoalRepository) GetUserPreference(ctx context.Context, userId int) (*string, error) {
+	row := r.pool.QueryRow(ctx, getUserPreference, userId)
+	var preference *string
+	err := row.Scan(&preference)
+	return preference, err
+}
@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/goals/model"
+)
+
+const (
+	PreferenceCardio = "CARDIO"
+	PreferenceWeight = "WEIGHT"
+)
+
+func activityType(t activityModel.ActivityTypeEnum) *activityModel.ActivityTypeEnum {
+	return &t
+}
+
+// goalSuggestions are the default goals offered per user preference. Cardio
+// follows the common 150 minutes per week guideline, weight training favors
+// regular strength sessions and a monthly calorie target.
+var goalSuggestions = map[string][]model.Goal{
+	PreferenceCardio: {
+		{Metric: model.GoalMetricEnumDuration, Period: model.GoalPeriodEnumWeek, Target: 150},
+		{Metric: model.GoalMetricEnumSessions, Period: model.GoalPeriodEnumWeek, Target: 3},
+		{Metric: model.GoalMetricEnumCalories, Period: model.GoalPeriodEnumMonth, Target: 2000},
+	},
+	PreferenceWeight: {
+		{Metric: model.GoalMetricEnumSessions, Period: model.GoalPeriodEnumWeek, Target: 3, ActivityType: activityType(activityModel.ActivityTypeEnumWeightTraining)},
+		{Metric: model.GoalMetricEnumDuration, Period: model.GoalPeriodEnumWeek, Target: 90},
+		{Metric: model.GoalMetricEnumCalories, Period: model.GoalPeriodEnumMonth, Target: 2000},
+	},
+}
+
+// defaultGoalSuggestions are offered to users who have not set a preference.
+var defaultGoalSuggestions = []model.Goal{
+	{Metric: model.GoalMetricEnumDuration, Period: model.GoalPeriodEnumWeek, Target: 150},
+	{Metric: model.GoalMetricEnumSessions, Period: model.GoalPeriodEnumWeek, Target: 3},
+}
@@ -0,0 +1,121 @@
+package usecase
+
+import (
+	"context"
+
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/goals/dto"
+	"fit-byte/internal/goals/model"
+	"fit-byte/internal/goals/repository"
+
+	"github.com/pkg/errors"
+)
+
+// ActivityTypeResolver checks that an activity type is a global type or one
+// of the user's custom types.
+type ActivityTypeResolver interface {
+	Resolve(ctx context.Context, userId int, name activityModel.ActivityTypeEnum) (activityModel.ActivityType, error)
+}
+
+type GoalUseCase struct {
+	goalRepo      repository.GoalRepository
+	activityTypes ActivityTypeResolver
+}
+
+func NewGoalUseCase(goalRepo repository.GoalRepository, activityTypes ActivityTypeResolver) *GoalUseCase {
+	return &GoalUseCase{goalRepo: goalRepo, activityTypes: activityTypes}
+}
+
+func (c *GoalUseCase) GetGoals(ctx context.Context, userId int) ([]model.GoalProgress, error) {
+	goals, err := c.goalRepo.ListGoals(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get goals")
+	}
+
+	return goals, nil
+}
+
+func (c *GoalUseCase) GetGoalById(ctx context.Context, goalId int, userId int) (*model.GoalProgress, error) {
+	goal, err := c.goalRepo.GetGoal(ctx, repository.GetAndDeleteGoalParams{
+		Id:     goalId,
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get goal")
+	}
+
+	return &goal, nil
+}
+
+func (c *GoalUseCase) CreateGoal(ctx context.Context, request *dto.CreateGoalRequest, userId int) (*model.GoalProgress, error) {
+	if request.ActivityType != nil {
+		if _, err := c.activityTypes.Resolve(ctx, userId, *request.ActivityType); err != nil {
+			return nil, err
+		}
+	}
+
+	arg := repository.CreateGoalParams{
+		Metric:       request.Metric,
+		Period:       request.Period,
+		Target:       request.Target,
+		ActivityType: request.ActivityType,
+		Category:     request.Category,
+		UserId:       userId,
+	}
+
+	goalId, err := c.goalRepo.CreateGoal(ctx, arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create goal")
+	}
+
+	return c.GetGoalById(ctx, goalId, userId)
+}
+
+func (c *GoalUseCase) UpdateGoal(ctx context.Context, request *dto.UpdateGoalRequest, goalId int, userId int) (*model.GoalProgress, error) {
+	if request.ActivityType.Value != nil {
+		if _, err := c.activityTypes.Resolve(ctx, userId, *request.ActivityType.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	arg := repository.UpdateGoalParams{
+		Metric:       request.Metric,
+		Period:       request.Period,
+		Target:       request.Target,
+		ActivityType: request.ActivityType,
+		Category:     request.Category,
+		GoalId:       goalId,
+		UserId:       userId,
+	}
+
+	if err := c.goalRepo.UpdateGoal(ctx, arg); err != nil {
+		return nil, errors.Wrap(err, "failed to update goal")
+	}
+
+	return c.GetGoalById(ctx, goalId, userId)
+}
+
+func (c *GoalUseCase) DeleteGoal(ctx context.Context, goalId int, userId int) error {
+	arg := repository.GetAndDeleteGoalParams{
+		Id:     goalId,
+		UserId: userId,
+	}
+
+	return c.goalRepo.DeleteGoal(ctx, arg)
+}
+
+// SuggestGoals returns default goals matching the user's preference.
+func (c *GoalUseCase) SuggestGoals(ctx context.Context, userId int) ([]model.Goal, error) {
+	preference, err := c.goalRepo.GetUserPreference(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user preference")
+	}
+
+	if preference != nil {
+		if suggestions, ok := goalSuggestions[*preference]; ok {
+			return suggestions, nil
+		}
+	}
+
+	return defaultGoalSuggestions, nil
+}
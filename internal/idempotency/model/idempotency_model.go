@@ -0,0 +1,22 @@
+package model
+
+import "time"
+
+type IdempotencyKey struct {
+	UserId              int
+	Key                 string
+	RequestMethod       string
+	RequestPath         string
+	RequestHash         string
+	ResponseStatus      *int
+	ResponseContentType *string
+	ResponseBody        []byte
+	CreatedAt           time.Time
+	ExpiresAt           time.Time
+}
+
+// Completed reports whether the original request finished and its response
+// can be replayed.
+func (k IdempotencyKey) Completed() bool {
+	return k.ResponseStatus != nil
+}
@@ -0,0 +1,192 @@
+package repository
+
+import (
+	"context"
+	"fit-byte/internal/idempotency/model"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type IdempotencyRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewIdempotencyRepository(pool *pgxpool.Pool) *IdempotencyRepository {
+	return &IdempotencyRepository{pool: pool}
+}
+
+// An expired key is taken over by the new request instead of blocking it.
+const queryReserveKey = `
+INSERT INTO idempotency_keys (
+	user_id,
+	idempotency_key,
+	request_method,
+	request_path,
+	request_hash,
+	expires_at
+) VALUES (
+	@userId, @key, @method, @path, @hash, @expiresAt
+)
+ON CONFLICT (user_id, idempotency_key) DO UPDATE
+SET
+	request_method = EXCLUDED.request_method,
+	request_path = EXCLUDED.request_path,
+	request_hash = EXCLUDED.request_hash,
+	response_status = NULL,
+	response_content_type = NULL,
+	response_body = NULL,
+	created_at = NOW(),
+	expires_at = EXCLUDED.expires_at
+WHERE idempotency_keys.expires_at < NOW()
+RETURNING idempotency_key
+`
+
+type ReserveKeyParams struct {
+	UserId        int
+	Key           string
+	RequestMethod string
+	RequestPath   string
+	RequestHash   string
+	ExpiresAt     time.Time
+}
+
+// ReserveKey claims a key for a new request. It returns false when the key is
+// already held by an earlier, unexpired request.
+func (r *IdempotencyRepository) ReserveKey(ctx context.Context, arg ReserveKeyParams) (bool, error) {
+	args := pgx.NamedArgs{
+		"userId":    arg.UserId,
+		"key":       arg.Key,
+		"method":    arg.RequestMethod,
+		"path":      arg.RequestPath,
+		"hash":      arg.RequestHash,
+		"expiresAt": arg.ExpiresAt,
+	}
+
+	var key string
+	err := r.pool.QueryRow(ctx, queryReserveKey, args).Scan(&key)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, errors.Wrap(err, "failed to reserve idempotency key")
+	}
+
+	return true, nil
+}
+
+const queryGetKey = `
+SELECT
+	user_id,
+	idempotency_key,
+	request_method,
+	request_path,
+	request_hash,
+	response_status,
+	response_content_type,
+	response_body,
+	created_at,
+	expires_at
+FROM idempotency_keys
+WHERE user_id = @userId AND idempotency_key = @key
+`
+
+func (r *IdempotencyRepository) GetKey(ctx context.Context, userId int, key string) (*model.IdempotencyKey, error) {
+	args := pgx.NamedArgs{
+		"userId": userId,
+		"key":    key,
+	}
+
+	var i model.IdempotencyKey
+	err := r.pool.QueryRow(ctx, queryGetKey, args).Scan(
+		&i.UserId,
+		&i.Key,
+		&i.RequestMethod,
+		&i.RequestPath,
+		&i.RequestHash,
+		&i.ResponseStatus,
+		&i.ResponseContentType,
+		&i.ResponseBody,
+		&i.CreatedAt,
+		&i.ExpiresAt,
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get idempotency key")
+	}
+
+	return &i, nil
+}
+
+const querySaveResponse = `
+UPDATE idempotency_keys
+SET
+	response_status = @status,
+	response_content_type = @contentType,
+	response_body = @body,
+	expires_at = @expiresAt
+WHERE user_id = @userId AND idempotency_key = @key
+`
+
+type SaveResponseParams struct {
+	UserId      int
+	Key         string
+	Status      int
+	ContentType string
+	Body        []byte
+	ExpiresAt   time.Time
+}
+
+// SaveResponse completes the request and keeps its response until ExpiresAt.
+func (r *IdempotencyRepository) SaveResponse(ctx context.Context, arg SaveResponseParams) error {
+	args := pgx.NamedArgs{
+		"userId":      arg.UserId,
+		"key":         arg.Key,
+		"status":      arg.Status,
+		"contentType": arg.ContentType,
+		"body":        arg.Body,
+		"expiresAt":   arg.ExpiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, querySaveResponse, args); err != nil {
+		return errors.Wrap(err, "failed to save idempotent response")
+	}
+
+	return nil
+}
+
+const queryDeleteKey = `
+DELETE FROM idempotency_keys WHERE user_id = @userId AND idempotency_key = @key
+`
+
+func (r *IdempotencyRepository) DeleteKey(ctx context.Context, userId int, key string) error {
+	args := pgx.NamedArgs{
+		"userId": userId,
+		"key":    key,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryDeleteKey, args); err != nil {
+		return errors.Wrap(err, "failed to delete idempotency key")
+	}
+
+	return nil
+}
+
+const queryDeleteExpiredKeys = `
+DELETE FROM idempotency_keys WHERE user_id = @userId AND expires_at < NOW()
+`
+
+// DeleteExpiredKeys purges a user's expired keys so the table does not grow
+// without bound.
+func (r *IdempotencyRepository) DeleteExpiredKeys(ctx context.Context, userId int) error {
+	args := pgx.NamedArgs{
+		"userId": userId,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryDeleteExpiredKeys, args); err != nil {
+		return errors.Wrap(err, "failed to delete expired idempotency keys")
+	}
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package custom_middleware
 
 import (
+	"context"
 	"net/http"
 
 	customErrors "fit-byte/pkg/custom-errors"
@@ -12,13 +13,20 @@ import (
 	"github.com/pkg/errors"
 )
 
+// TokenRevoker tells whether a validly signed token has been logged out.
+type TokenRevoker interface {
+	IsRevoked(ctx context.Context, claim *jwt.JWTClaim) (bool, error)
+}
+
 type AuthConfig struct {
-	Env *dotenv.Env
+	Env     *dotenv.Env
+	Revoker TokenRevoker
 }
 
-func NewAuthMiddleware(env *dotenv.Env) *AuthConfig {
+func NewAuthMiddleware(env *dotenv.Env, revoker TokenRevoker) *AuthConfig {
 	return &AuthConfig{
-		Env: env,
+		Env:     env,
+		Revoker: revoker,
 	}
 }
 
@@ -36,6 +44,17 @@ func (a *AuthConfig) Authenticate() echo.MiddlewareFunc {
 				return ctx.JSON(response.WriteErrorResponse(err))
 			}
 
+			// a deleted user or a failed lookup must not leak as 404 or 500
+			revoked, err := a.Revoker.IsRevoked(ctx.Request().Context(), claim)
+			if err != nil {
+				err = errors.Wrap(customErrors.ErrUnauthorized, "unable to verify token")
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+			if revoked {
+				err = errors.Wrap(customErrors.ErrUnauthorized, "token has been revoked")
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+
 			ctx.Set("user", claim)
 
 			// default user passing middleware if token is valid
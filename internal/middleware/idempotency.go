@@ -0,0 +1,234 @@
+package custom_middleware
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"fit-byte/internal/idempotency/repository"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/dotenv"
+	jwt "fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	HeaderIdempotencyKey      = "Idempotency-Key"
+	HeaderIdempotentReplayed  = "Idempotent-Replayed"
+	DEFAULT_IDEMPOTENCY_TTL   = 24 * time.Hour
+	MAX_IDEMPOTENCY_KEY_BYTES = 255
+	MAX_IDEMPOTENT_BODY_BYTES = 2 * 1024 * 1024
+	// a key stays reserved this long while its request is processed, so a
+	// crashed request does not block retries until the TTL
+	IDEMPOTENCY_PROCESSING_LEASE = 2 * time.Minute
+)
+
+type IdempotencyConfig struct {
+	Repo *repository.IdempotencyRepository
+	Log  *logrus.Logger
+	TTL  time.Duration
+}
+
+func NewIdempotencyMiddleware(repo *repository.IdempotencyRepository, env *dotenv.Env, log *logrus.Logger) *IdempotencyConfig {
+	ttl, err := time.ParseDuration(env.IDEMPOTENCY_TTL)
+	if err != nil || ttl <= 0 {
+		ttl = DEFAULT_IDEMPOTENCY_TTL
+	}
+
+	return &IdempotencyConfig{
+		Repo: repo,
+		Log:  log,
+		TTL:  ttl,
+	}
+}
+
+// Idempotent replays the stored response when a request is retried with the
+// same Idempotency-Key. It must run after Authenticate since keys are scoped
+// per user.
+func (i *IdempotencyConfig) Idempotent() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			key := ctx.Request().Header.Get(HeaderIdempotencyKey)
+			if key == "" {
+				return next(ctx)
+			}
+
+			if len(key) > MAX_IDEMPOTENCY_KEY_BYTES {
+				err := errors.Wrap(customErrors.ErrBadRequest, "idempotency key is too long")
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+
+			user := ctx.Get("user").(*jwt.JWTClaim)
+			req := ctx.Request()
+
+			body, err := io.ReadAll(http.MaxBytesReader(ctx.Response(), req.Body, MAX_IDEMPOTENT_BODY_BYTES))
+			if err != nil {
+				err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			if err := i.Repo.DeleteExpiredKeys(req.Context(), user.ID); err != nil {
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+
+			hash, err := requestHash(req.Method, ctx.Path(), req.Header.Get(echo.HeaderContentType), body)
+			if err != nil {
+				err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+			reserved, err := i.Repo.ReserveKey(req.Context(), repository.ReserveKeyParams{
+				UserId:        user.ID,
+				Key:           key,
+				RequestMethod: req.Method,
+				RequestPath:   ctx.Path(),
+				RequestHash:   hash,
+				ExpiresAt:     time.Now().Add(IDEMPOTENCY_PROCESSING_LEASE),
+			})
+			if err != nil {
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+
+			if !reserved {
+				return i.replay(ctx, user.ID, key, hash)
+			}
+
+			recorder := &responseRecorder{ResponseWriter: ctx.Response().Writer}
+			ctx.Response().Writer = recorder
+
+			defer func() {
+				if r := recover(); r != nil {
+					i.release(user.ID, key)
+					panic(r)
+				}
+			}()
+
+			if err := next(ctx); err != nil {
+				i.release(user.ID, key)
+				return err
+			}
+
+			// server errors are not stored so the client can retry them
+			status := ctx.Response().Status
+			if status >= http.StatusInternalServerError {
+				i.release(user.ID, key)
+				return nil
+			}
+
+			err = i.Repo.SaveResponse(context.Background(), repository.SaveResponseParams{
+				UserId:      user.ID,
+				Key:         key,
+				Status:      status,
+				ContentType: ctx.Response().Header().Get(echo.HeaderContentType),
+				Body:        recorder.body.Bytes(),
+				ExpiresAt:   time.Now().Add(i.TTL),
+			})
+			if err != nil {
+				i.Log.WithError(err).Error("failed to save idempotent response")
+			}
+
+			return nil
+		}
+	}
+}
+
+func (i *IdempotencyConfig) replay(ctx echo.Context, userId int, key string, hash string) error {
+	stored, err := i.Repo.GetKey(ctx.Request().Context(), userId, key)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if stored.RequestHash != hash {
+		err := errors.Wrap(customErrors.ErrUnprocessableEntity, "idempotency key was used with a different request")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if !stored.Completed() {
+		err := errors.Wrap(customErrors.ErrConflict, "a request with this idempotency key is still in progress")
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	contentType := echo.MIMEApplicationJSON
+	if stored.ResponseContentType != nil {
+		contentType = *stored.ResponseContentType
+	}
+
+	ctx.Response().Header().Set(HeaderIdempotentReplayed, "true")
+	return ctx.Blob(*stored.ResponseStatus, contentType, stored.ResponseBody)
+}
+
+// release frees the key so a failed request can be retried with it.
+func (i *IdempotencyConfig) release(userId int, key string) {
+	if err := i.Repo.DeleteKey(context.Background(), userId, key); err != nil {
+		i.Log.WithError(err).Error("failed to release idempotency key")
+	}
+}
+
+// requestHash identifies the request a key was used with. Multipart bodies are
+// hashed by their parts, since every client picks a new random boundary.
+func requestHash(method, path, contentType string, body []byte) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(method + " " + path + "\n"))
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil || !strings.HasPrefix(mediaType, "multipart/") {
+		hash.Write(body)
+		return hex.EncodeToString(hash.Sum(nil)), nil
+	}
+
+	reader := multipart.NewReader(bytes.NewReader(body), params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+
+		content, err := io.ReadAll(part)
+		if err != nil {
+			return "", err
+		}
+		fmt.Fprintf(hash, "%q %q %q %d\n", part.FormName(), part.FileName(), part.Header.Get(echo.HeaderContentType), len(content))
+		hash.Write(content)
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// responseRecorder keeps a copy of the response body while writing it through.
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+func (r *responseRecorder) Flush() {
+	http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+func (r *responseRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	return http.NewResponseController(r.ResponseWriter).Hijack()
+}
+
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
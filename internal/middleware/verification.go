@@ -0,0 +1,38 @@
+package custom_middleware
+
+import (
+	"context"
+
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/labstack/echo/v4"
+)
+
+// UploadPolicy decides whether a user may upload files.
+type UploadPolicy interface {
+	CheckUpload(ctx context.Context, userId int) error
+}
+
+type VerificationConfig struct {
+	Policy UploadPolicy
+}
+
+func NewVerificationMiddleware(policy UploadPolicy) *VerificationConfig {
+	return &VerificationConfig{
+		Policy: policy,
+	}
+}
+
+// UploadAllowed must run after Authenticate.
+func (v *VerificationConfig) UploadAllowed() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(ctx echo.Context) error {
+			userData := ctx.Get("user").(*jwt.JWTClaim)
+			if err := v.Policy.CheckUpload(ctx.Request().Context(), userData.ID); err != nil {
+				return ctx.JSON(response.WriteErrorResponse(err))
+			}
+			return next(ctx)
+		}
+	}
+}
@@ -0,0 +1,86 @@
+package dto
+
+import (
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/plannedworkouts/model"
+	"fit-byte/pkg/helper"
+	"time"
+)
+
+type PlannedWorkoutResponse struct {
+	PlannedWorkoutId  string                         `json:"plannedWorkoutId"`
+	ActivityType      activityModel.ActivityTypeEnum `json:"activityType"`
+	ScheduledAt       string                         `json:"scheduledAt"`
+	DurationInMinutes int                            `json:"durationInMinutes"`
+	Recurrence        model.RecurrenceEnum           `json:"recurrence"`
+	DaysOfWeek        []int                          `json:"daysOfWeek"`
+	RecurrenceUntil   *string                        `json:"recurrenceUntil"`
+	Notes             *string                        `json:"notes"`
+	CreatedAt         string                         `json:"createdAt"`
+	UpdatedAt         string                         `json:"updatedAt"`
+}
+
+// CreatePlannedWorkoutRequest schedules a workout. DaysOfWeek uses 0 for
+// Sunday through 6 for Saturday and is required for WEEKLY recurrences.
+type CreatePlannedWorkoutRequest struct {
+	ActivityType      activityModel.ActivityTypeEnum `json:"activityType" validate:"required,activity_type"`
+	ScheduledAt       time.Time                      `json:"scheduledAt" validate:"required,time_validator"`
+	DurationInMinutes int                            `json:"durationInMinutes" validate:"required,min=1"`
+	Recurrence        model.RecurrenceEnum           `json:"recurrence" validate:"omitempty,oneof=NONE DAILY WEEKDAYS WEEKLY"`
+	DaysOfWeek        []int                          `json:"daysOfWeek" validate:"omitempty,unique,dive,min=0,max=6"`
+	RecurrenceUntil   *time.Time                     `json:"recurrenceUntil" validate:"omitempty,time_validator"`
+	Notes             *string                        `json:"notes" validate:"omitempty,max=500"`
+}
+
+// UpdatePlannedWorkoutRequest only changes the fields present in the body.
+// Sending null clears recurrenceUntil and notes.
+type UpdatePlannedWorkoutRequest struct {
+	ActivityType      *activityModel.ActivityTypeEnum `json:"activityType" validate:"omitempty,activity_type"`
+	ScheduledAt       *time.Time                      `json:"scheduledAt" validate:"omitempty,time_validator"`
+	DurationInMinutes *int                            `json:"durationInMinutes" validate:"omitempty,min=1"`
+	Recurrence        *model.RecurrenceEnum           `json:"recurrence" validate:"omitempty,oneof=NONE DAILY WEEKDAYS WEEKLY"`
+	DaysOfWeek        []int                           `json:"daysOfWeek" validate:"omitempty,unique,dive,min=0,max=6"`
+	RecurrenceUntil   helper.Nullable[time.Time]      `json:"recurrenceUntil" validate:"omitempty,time_validator"`
+	Notes             helper.Nullable[string]         `json:"notes" validate:"omitempty,max=500"`
+}
+
+type GetPlannedWorkoutsRequest struct {
+	Limit  int `query:"limit" validate:"omitempty,min=0"`
+	Offset int `query:"offset" validate:"omitempty,min=0"`
+}
+
+// CompletePlannedWorkoutRequest logs an occurrence as an activity. Omitted
+// fields default to the occurrence time and the planned duration and notes.
+type CompletePlannedWorkoutRequest struct {
+	OccurrenceAt        *time.Time `json:"occurrenceAt" validate:"omitempty,time_validator"`
+	DoneAt              *time.Time `json:"doneAt" validate:"omitempty,time_validator"`
+	DurationInMinutes   *int       `json:"durationInMinutes" validate:"omitempty,min=1"`
+	DistanceMeters      *float64   `json:"distanceMeters" validate:"omitempty,gt=0,max=1000000"`
+	AvgHeartRate        *int       `json:"avgHeartRate" validate:"omitempty,min=30,max=250"`
+	MaxHeartRate        *int       `json:"maxHeartRate" validate:"omitempty,min=30,max=250"`
+	ElevationGainMeters *float64   `json:"elevationGainMeters" validate:"omitempty,min=0,max=100000"`
+	Notes               *string    `json:"notes" validate:"omitempty,max=500"`
+}
+
+type GetScheduleRequest struct {
+	From *time.Time `query:"from" validate:"omitempty,time_validator"`
+	To   *time.Time `query:"to" validate:"omitempty,time_validator"`
+}
+
+type OccurrenceResponse struct {
+	PlannedWorkoutId  string                         `json:"plannedWorkoutId"`
+	ActivityType      activityModel.ActivityTypeEnum `json:"activityType"`
+	ScheduledAt       string                         `json:"scheduledAt"`
+	DurationInMinutes int                            `json:"durationInMinutes"`
+	Recurrence        model.RecurrenceEnum           `json:"recurrence"`
+	Notes             *string                        `json:"notes"`
+	Completed         bool                           `json:"completed"`
+	ActivityId        *string                        `json:"activityId"`
+	CompletedAt       *string                        `json:"completedAt"`
+}
+
+type ScheduleResponse struct {
+	From        string               `json:"from"`
+	To          string               `json:"to"`
+	Occurrences []OccurrenceResponse `json:"occurrences"`
+}
@@ -0,0 +1,221 @@
+package handler
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	activityConverter "fit-byte/internal/activity/model/converter"
+	"fit-byte/internal/plannedworkouts/dto"
+	"fit-byte/internal/plannedworkouts/model/converter"
+	"fit-byte/internal/plannedworkouts/usecase"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+const DEFAULT_LIMIT = 5
+
+type PlannedWorkoutHandler struct {
+	UseCase  usecase.PlannedWorkoutUseCase
+	Validate *validator.Validate
+}
+
+func NewPlannedWorkoutHandler(useCase usecase.PlannedWorkoutUseCase, validate *validator.Validate) *PlannedWorkoutHandler {
+	return &PlannedWorkoutHandler{
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func plannedWorkoutId(ctx echo.Context) (int, error) {
+	id, err := strconv.Atoi(ctx.Param("plannedWorkoutId"))
+	if err != nil {
+		return 0, errors.Wrap(customErrors.ErrNotFound, "planned workout id required")
+	}
+	return id, nil
+}
+
+func (c *PlannedWorkoutHandler) GetPlannedWorkouts(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.GetPlannedWorkoutsRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if request.Limit == 0 {
+		request.Limit = DEFAULT_LIMIT
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	plannedWorkouts, err := c.UseCase.GetPlannedWorkouts(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToPlannedWorkoutResponseList(plannedWorkouts)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PlannedWorkoutHandler) CreatePlannedWorkout(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.CreatePlannedWorkoutRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	plannedWorkout, err := c.UseCase.CreatePlannedWorkout(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToPlannedWorkoutResponse(*plannedWorkout)
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (c *PlannedWorkoutHandler) GetPlannedWorkoutById(ctx echo.Context) error {
+	id, err := plannedWorkoutId(ctx)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	plannedWorkout, err := c.UseCase.GetPlannedWorkoutById(ctx.Request().Context(), id, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToPlannedWorkoutResponse(*plannedWorkout)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PlannedWorkoutHandler) UpdatePlannedWorkout(ctx echo.Context) error {
+	id, err := plannedWorkoutId(ctx)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	var request = new(dto.UpdatePlannedWorkoutRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	plannedWorkout, err := c.UseCase.UpdatePlannedWorkout(ctx.Request().Context(), request, id, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToPlannedWorkoutResponse(*plannedWorkout)
+
+	return ctx.JSON(http.StatusOK, response)
+}
+
+func (c *PlannedWorkoutHandler) DeletePlannedWorkout(ctx echo.Context) error {
+	id, err := plannedWorkoutId(ctx)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	err = c.UseCase.DeletePlannedWorkout(ctx.Request().Context(), id, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "deleted",
+	})
+}
+
+func (c *PlannedWorkoutHandler) CompletePlannedWorkout(ctx echo.Context) error {
+	id, err := plannedWorkoutId(ctx)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	var request = new(dto.CompletePlannedWorkoutRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+	activity, records, err := c.UseCase.CompletePlannedWorkout(ctx.Request().Context(), request, id, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := activityConverter.ToActivityWithRecordsResponse(*activity, records)
+
+	return ctx.JSON(http.StatusCreated, response)
+}
+
+func (c *PlannedWorkoutHandler) GetSchedule(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.GetScheduleRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	from := time.Now()
+	if request.From != nil {
+		from = *request.From
+	}
+	to := from.Add(usecase.DEFAULT_SCHEDULE_RANGE)
+	if request.To != nil {
+		to = *request.To
+	}
+
+	occurrences, err := c.UseCase.GetSchedule(ctx.Request().Context(), from, to, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToScheduleResponse(from, to, occurrences)
+
+	return ctx.JSON(http.StatusOK, response)
+}
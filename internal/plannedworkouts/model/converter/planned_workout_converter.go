@@ -0,0 +1,79 @@
+package converter
+
+import (
+	"fit-byte/internal/plannedworkouts/dto"
+	"fit-byte/internal/plannedworkouts/model"
+	"fit-byte/pkg/helper"
+	"strconv"
+	"time"
+)
+
+func ToPlannedWorkoutResponse(plannedWorkout model.PlannedWorkout) dto.PlannedWorkoutResponse {
+	var recurrenceUntil *string
+	if plannedWorkout.RecurrenceUntil != nil {
+		formatted := helper.FormatTimeToUTC(*plannedWorkout.RecurrenceUntil)
+		recurrenceUntil = &formatted
+	}
+
+	daysOfWeek := plannedWorkout.DaysOfWeek
+	if daysOfWeek == nil {
+		daysOfWeek = []int{}
+	}
+
+	return dto.PlannedWorkoutResponse{
+		PlannedWorkoutId:  strconv.Itoa(plannedWorkout.ID),
+		ActivityType:      plannedWorkout.ActivityType,
+		ScheduledAt:       helper.FormatTimeToUTC(plannedWorkout.ScheduledAt),
+		DurationInMinutes: plannedWorkout.DurationInMinutes,
+		Recurrence:        plannedWorkout.Recurrence,
+		DaysOfWeek:        daysOfWeek,
+		RecurrenceUntil:   recurrenceUntil,
+		Notes:             plannedWorkout.Notes,
+		CreatedAt:         helper.FormatTimeToUTC(plannedWorkout.CreatedAt),
+		UpdatedAt:         helper.FormatTimeToUTC(plannedWorkout.UpdatedAt),
+	}
+}
+
+func ToPlannedWorkoutResponseList(plannedWorkouts []model.PlannedWorkout) []dto.PlannedWorkoutResponse {
+	responses := make([]dto.PlannedWorkoutResponse, len(plannedWorkouts))
+	for i, plannedWorkout := range plannedWorkouts {
+		responses[i] = ToPlannedWorkoutResponse(plannedWorkout)
+	}
+	return responses
+}
+
+func ToOccurrenceResponse(occurrence model.Occurrence) dto.OccurrenceResponse {
+	response := dto.OccurrenceResponse{
+		PlannedWorkoutId:  strconv.Itoa(occurrence.PlannedWorkout.ID),
+		ActivityType:      occurrence.PlannedWorkout.ActivityType,
+		ScheduledAt:       helper.FormatTimeToUTC(occurrence.At),
+		DurationInMinutes: occurrence.PlannedWorkout.DurationInMinutes,
+		Recurrence:        occurrence.PlannedWorkout.Recurrence,
+		Notes:             occurrence.PlannedWorkout.Notes,
+		Completed:         occurrence.Completion != nil,
+	}
+
+	if occurrence.Completion != nil {
+		completedAt := helper.FormatTimeToUTC(occurrence.Completion.CompletedAt)
+		response.CompletedAt = &completedAt
+		if occurrence.Completion.ActivityId != nil {
+			activityId := strconv.Itoa(*occurrence.Completion.ActivityId)
+			response.ActivityId = &activityId
+		}
+	}
+
+	return response
+}
+
+func ToScheduleResponse(from, to time.Time, occurrences []model.Occurrence) dto.ScheduleResponse {
+	responses := make([]dto.OccurrenceResponse, len(occurrences))
+	for i, occurrence := range occurrences {
+		responses[i] = ToOccurrenceResponse(occurrence)
+	}
+
+	return dto.ScheduleResponse{
+		From:        helper.FormatTimeToUTC(from),
+		To:          helper.FormatTimeToUTC(to),
+		Occurrences: responses,
+	}
+}
@@ -0,0 +1,62 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	activityModel "fit-byte/internal/activity/model"
+)
+
+type RecurrenceEnum string
+
+const (
+	RecurrenceEnumNone     RecurrenceEnum = "NONE"
+	RecurrenceEnumDaily    RecurrenceEnum = "DAILY"
+	RecurrenceEnumWeekdays RecurrenceEnum = "WEEKDAYS"
+	RecurrenceEnumWeekly   RecurrenceEnum = "WEEKLY"
+)
+
+func (e *RecurrenceEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RecurrenceEnum(s)
+	case string:
+		*e = RecurrenceEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RecurrenceEnum: %T", src)
+	}
+	return nil
+}
+
+// PlannedWorkout is a workout scheduled at ScheduledAt. Recurring workouts
+// repeat at the same local time of day, on the days given by Recurrence, until
+// RecurrenceUntil. DaysOfWeek holds time.Weekday values and is only used by
+// WEEKLY recurrences.
+type PlannedWorkout struct {
+	ID                int
+	UserId            int
+	ActivityType      activityModel.ActivityTypeEnum
+	ScheduledAt       time.Time
+	DurationInMinutes int
+	Recurrence        RecurrenceEnum
+	DaysOfWeek        []int
+	RecurrenceUntil   *time.Time
+	Notes             *string
+	CreatedAt         time.Time
+	UpdatedAt         time.Time
+}
+
+// Completion links an occurrence of a planned workout to the activity logged for it.
+type Completion struct {
+	PlannedWorkoutId int
+	OccurrenceAt     time.Time
+	ActivityId       *int
+	CompletedAt      time.Time
+}
+
+// Occurrence is a single scheduled instance of a planned workout.
+type Occurrence struct {
+	PlannedWorkout PlannedWorkout
+	At             time.Time
+	Completion     *Completion
+}
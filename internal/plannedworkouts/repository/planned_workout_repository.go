@@ -0,0 +1,344 @@
+package repository
+
+import (
+	"context"
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/plannedworkouts/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type PlannedWorkoutRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewPlannedWorkoutRepository(pool *pgxpool.Pool) *PlannedWorkoutRepository {
+	return &PlannedWorkoutRepository{pool: pool}
+}
+
+const plannedWorkoutColumns = `id, user_id, activity_type, scheduled_at, duration_in_minutes, recurrence, days_of_week, recurrence_until, notes, created_at, updated_at`
+
+func scanPlannedWorkout(row pgx.Row) (model.PlannedWorkout, error) {
+	var i model.PlannedWorkout
+	err := row.Scan(
+		&i.ID,
+		&i.UserId,
+		&i.ActivityType,
+		&i.ScheduledAt,
+		&i.DurationInMinutes,
+		&i.Recurrence,
+		&i.DaysOfWeek,
+		&i.RecurrenceUntil,
+		&i.Notes,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+func collectPlannedWorkouts(rows pgx.Rows) ([]model.PlannedWorkout, error) {
+	defer rows.Close()
+	var items []model.PlannedWorkout
+	for rows.Next() {
+		i, err := scanPlannedWorkout(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const createPlannedWorkout = `-- name: CreatePlannedWorkout :one
+INSERT INTO planned_workouts (
+  activity_type,
+  scheduled_at,
+  duration_in_minutes,
+  recurrence,
+  days_of_week,
+  recurrence_until,
+  notes,
+  user_id
+) VALUES (
+  $1, $2, $3, $4, $5, $6, $7, $8
+) RETURNING ` + plannedWorkoutColumns
+
+type CreatePlannedWorkoutParams struct {
+	ActivityType      activityModel.ActivityTypeEnum
+	ScheduledAt       time.Time
+	DurationInMinutes int
+	Recurrence        model.RecurrenceEnum
+	DaysOfWeek        []int
+	RecurrenceUntil   *time.Time
+	Notes             *string
+	UserId            int
+}
+
+func (r *PlannedWorkoutRepository) CreatePlannedWorkout(ctx context.Context, arg CreatePlannedWorkoutParams) (model.PlannedWorkout, error) {
+	row := r.pool.QueryRow(ctx, createPlannedWorkout,
+		arg.ActivityType,
+		arg.ScheduledAt,
+		arg.DurationInMinutes,
+		arg.Recurrence,
+		arg.DaysOfWeek,
+		arg.RecurrenceUntil,
+		arg.Notes,
+		arg.UserId,
+	)
+	return scanPlannedWorkout(row)
+}
+
+const listPlannedWorkouts = `-- name: ListPlannedWorkouts :many
+SELECT ` + plannedWorkoutColumns + ` FROM planned_workouts
+WHERE (user_id = $3::bigint)
+ORDER BY scheduled_at, id
+LIMIT $1
+OFFSET $2
+`
+
+type ListPlannedWorkoutsParams struct {
+	Limit  int
+	Offset int
+	UserId int
+}
+
+func (r *PlannedWorkoutRepository) ListPlannedWorkouts(ctx context.Context, arg ListPlannedWorkoutsParams) ([]model.PlannedWorkout, error) {
+	rows, err := r.pool.Query(ctx, listPlannedWorkouts, arg.Limit, arg.Offset, arg.UserId)
+	if err != nil {
+		return nil, err
+	}
+	return collectPlannedWorkouts(rows)
+}
+
+const listPlannedWorkoutsInRange = `-- name: ListPlannedWorkoutsInRange :many
+SELECT ` + plannedWorkoutColumns + ` FROM planned_workouts
+WHERE (user_id = $1::bigint)
+  AND scheduled_at < $3::timestamptz
+  AND (
+    (recurrence = 'NONE' AND scheduled_at >= $2::timestamptz)
+    OR (recurrence <> 'NONE' AND (recurrence_until IS NULL OR recurrence_until >= $2::timestamptz))
+  )
+ORDER BY scheduled_at, id
+`
+
+// ListPlannedWorkoutsInRange returns the planned workouts that may have an
+// occurrence in [from, to).
+func (r *PlannedWorkoutRepository) ListPlannedWorkoutsInRange(ctx context.Context, userId int, from, to time.Time) ([]model.PlannedWorkout, error) {
+	rows, err := r.pool.Query(ctx, listPlannedWorkoutsInRange, userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	return collectPlannedWorkouts(rows)
+}
+
+const getPlannedWorkout = `-- name: GetPlannedWorkout :one
+SELECT ` + plannedWorkoutColumns + ` FROM planned_workouts
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+LIMIT 1
+`
+
+type GetAndDeletePlannedWorkoutParams struct {
+	Id     int
+	UserId int
+}
+
+func (r *PlannedWorkoutRepository) GetPlannedWorkout(ctx context.Context, arg GetAndDeletePlannedWorkoutParams) (model.PlannedWorkout, error) {
+	row := r.pool.QueryRow(ctx, getPlannedWorkout, arg.Id, arg.UserId)
+	return scanPlannedWorkout(row)
+}
+
+const updatePlannedWorkout = `-- name: UpdatePlannedWorkout :one
+UPDATE planned_workouts
+SET
+  activity_type = $1,
+  scheduled_at = $2,
+  duration_in_minutes = $3,
+  recurrence = $4,
+  days_of_week = $5,
+  recurrence_until = $6,
+  notes = $7
+WHERE (id = $8::bigint)
+  AND (user_id = $9::bigint)
+RETURNING ` + plannedWorkoutColumns
+
+type UpdatePlannedWorkoutParams struct {
+	CreatePlannedWorkoutParams
+	Id int
+}
+
+// UpdatePlannedWorkout overwrites every field of a planned workout.
+func (r *PlannedWorkoutRepository) UpdatePlannedWorkout(ctx context.Context, arg UpdatePlannedWorkoutParams) (model.PlannedWorkout, error) {
+	row := r.pool.QueryRow(ctx, updatePlannedWorkout,
+		arg.ActivityType,
+		arg.ScheduledAt,
+		arg.DurationInMinutes,
+		arg.Recurrence,
+		arg.DaysOfWeek,
+		arg.RecurrenceUntil,
+		arg.Notes,
+		arg.Id,
+		arg.UserId,
+	)
+	return scanPlannedWorkout(row)
+}
+
+const deletePlannedWorkout = `-- name: DeletePlannedWorkout :execrows
+DELETE FROM planned_workouts
+WHERE (id = $1::bigint)
+  AND (user_id = $2::bigint)
+`
+
+// DeletePlannedWorkout returns pgx.ErrNoRows when the planned workout does not
+// exist. Activities logged for it are kept.
+func (r *PlannedWorkoutRepository) DeletePlannedWorkout(ctx context.Context, arg GetAndDeletePlannedWorkoutParams) error {
+	tag, err := r.pool.Exec(ctx, deletePlannedWorkout, arg.Id, arg.UserId)
+	if err != nil {
+		return errors.Wrap(err, "failed to execute delete statements")
+	}
+	if tag.RowsAffected() == 0 {
+		return pgx.ErrNoRows
+	}
+
+	return nil
+}
+
+const completionColumns = `planned_workout_id, occurrence_at, activity_id, completed_at`
+
+func scanCompletion(row pgx.Row) (model.Completion, error) {
+	var i model.Completion
+	err := row.Scan(
+		&i.PlannedWorkoutId,
+		&i.OccurrenceAt,
+		&i.ActivityId,
+		&i.CompletedAt,
+	)
+	return i, err
+}
+
+const createCompletion = `-- name: CreateCompletion :one
+INSERT INTO planned_workout_completions (
+  planned_workout_id,
+  occurrence_at
+) VALUES (
+  $1, $2
+) RETURNING ` + completionColumns
+
+// CreateCompletion claims an occurrence before its activity is logged. It
+// returns customErrors.ErrConflict when the occurrence is already completed.
+func (r *PlannedWorkoutRepository) CreateCompletion(ctx context.Context, plannedWorkoutId int, occurrenceAt time.Time) (model.Completion, error) {
+	row := r.pool.QueryRow(ctx, createCompletion, plannedWorkoutId, occurrenceAt)
+	i, err := scanCompletion(row)
+	if customErrors.GetPgErrCode(err) == customErrors.UniqueViolation {
+		return i, errors.Wrap(customErrors.ErrConflict, "occurrence already completed")
+	}
+	return i, err
+}
+
+const setCompletionActivity = `-- name: SetCompletionActivity :one
+UPDATE planned_workout_completions
+SET activity_id = $3::bigint
+WHERE (planned_workout_id = $1::bigint)
+  AND (occurrence_at = $2::timestamptz)
+RETURNING ` + completionColumns
+
+func (r *PlannedWorkoutRepository) SetCompletionActivity(ctx context.Context, plannedWorkoutId int, occurrenceAt time.Time, activityId int) (model.Completion, error) {
+	row := r.pool.QueryRow(ctx, setCompletionActivity, plannedWorkoutId, occurrenceAt, activityId)
+	return scanCompletion(row)
+}
+
+const deleteCompletion = `-- name: DeleteCompletion :exec
+DELETE FROM planned_workout_completions
+WHERE (planned_workout_id = $1::bigint)
+  AND (occurrence_at = $2::timestamptz)
+`
+
+func (r *PlannedWorkoutRepository) DeleteCompletion(ctx context.Context, plannedWorkoutId int, occurrenceAt time.Time) error {
+	_, err := r.pool.Exec(ctx, deleteCompletion, plannedWorkoutId, occurrenceAt)
+	return err
+}
+
+const deleteCompletions = `-- name: DeleteCompletions :exec
+DELETE FROM planned_workout_completions
+WHERE (planned_workout_id = $1::bigint)
+  AND occurrence_at = ANY($2::timestamptz[])
+`
+
+func (r *PlannedWorkoutRepository) DeleteCompletions(ctx context.Context, plannedWorkoutId int, occurrences []time.Time) error {
+	_, err := r.pool.Exec(ctx, deleteCompletions, plannedWorkoutId, occurrences)
+	return err
+}
+
+const listPlannedWorkoutCompletions = `-- name: ListPlannedWorkoutCompletions :many
+SELECT ` + completionColumns + ` FROM planned_workout_completions
+WHERE (planned_workout_id = $1::bigint)
+`
+
+func (r *PlannedWorkoutRepository) ListPlannedWorkoutCompletions(ctx context.Context, plannedWorkoutId int) ([]model.Completion, error) {
+	rows, err := r.pool.Query(ctx, listPlannedWorkoutCompletions, plannedWorkoutId)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.Completion
+	for rows.Next() {
+		i, err := scanCompletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listCompletions = `-- name: ListCompletions :many
+SELECT c.planned_workout_id, c.occurrence_at, c.activity_id, c.completed_at
+FROM planned_workout_completions c
+JOIN planned_workouts p ON p.id = c.planned_workout_id
+WHERE (p.user_id = $1::bigint)
+  AND c.occurrence_at >= $2::timestamptz
+  AND c.occurrence_at < $3::timestamptz
+`
+
+// ListCompletions returns the completions of occurrences in [from, to).
+func (r *PlannedWorkoutRepository) ListCompletions(ctx context.Context, userId int, from, to time.Time) ([]model.Completion, error) {
+	rows, err := r.pool.Query(ctx, listCompletions, userId, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []model.Completion
+	for rows.Next() {
+		i, err := scanCompletion(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getUserTimeZone = `-- name: GetUserTimeZone :one
+SELECT time_zone FROM users
+WHERE (id = $1::bigint)
+LIMIT 1
+`
+
+func (r *PlannedWorkoutRepository) GetUserTimeZone(ctx context.Context, userId int) (string, error) {
+	var timeZone string
+	err := r.pool.QueryRow(ctx, getUserTimeZone, userId).Scan(&timeZone)
+	return timeZone, err
+}
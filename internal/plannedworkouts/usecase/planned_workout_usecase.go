@@ -0,0 +1,324 @@
+package usecase
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	activityDto "fit-byte/internal/activity/dto"
+	activityModel "fit-byte/internal/activity/model"
+	activityUsecase "fit-byte/internal/activity/usecase"
+	"fit-byte/internal/plannedworkouts/dto"
+	"fit-byte/internal/plannedworkouts/model"
+	"fit-byte/internal/plannedworkouts/repository"
+	recordModel "fit-byte/internal/records/model"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/helper"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	DEFAULT_SCHEDULE_RANGE = 7 * 24 * time.Hour
+	MAX_SCHEDULE_RANGE     = 92 * 24 * time.Hour
+)
+
+type PlannedWorkoutUseCase struct {
+	plannedWorkoutRepo repository.PlannedWorkoutRepository
+	activityUseCase    activityUsecase.ActivityUseCase
+	log                *logrus.Logger
+}
+
+func NewPlannedWorkoutUseCase(plannedWorkoutRepo repository.PlannedWorkoutRepository, activityUseCase activityUsecase.ActivityUseCase, log *logrus.Logger) *PlannedWorkoutUseCase {
+	return &PlannedWorkoutUseCase{
+		plannedWorkoutRepo: plannedWorkoutRepo,
+		activityUseCase:    activityUseCase,
+		log:                log,
+	}
+}
+
+func validateRecurrence(arg repository.CreatePlannedWorkoutParams) error {
+	if arg.Recurrence == model.RecurrenceEnumWeekly && len(arg.DaysOfWeek) == 0 {
+		return errors.Wrap(customErrors.ErrBadRequest, "daysOfWeek is required for WEEKLY recurrence")
+	}
+	if arg.Recurrence != model.RecurrenceEnumWeekly && len(arg.DaysOfWeek) > 0 {
+		return errors.Wrap(customErrors.ErrBadRequest, "daysOfWeek is only allowed for WEEKLY recurrence")
+	}
+	if arg.Recurrence == model.RecurrenceEnumNone && arg.RecurrenceUntil != nil {
+		return errors.Wrap(customErrors.ErrBadRequest, "recurrenceUntil requires a recurrence")
+	}
+	if arg.RecurrenceUntil != nil && arg.RecurrenceUntil.Before(arg.ScheduledAt) {
+		return errors.Wrap(customErrors.ErrBadRequest, "recurrenceUntil must not be before scheduledAt")
+	}
+	return nil
+}
+
+func (c *PlannedWorkoutUseCase) userLocation(ctx context.Context, userId int) (*time.Location, error) {
+	timeZone, err := c.plannedWorkoutRepo.GetUserTimeZone(ctx, userId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get user time zone")
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+	if err != nil {
+		return time.UTC, nil
+	}
+	return loc, nil
+}
+
+func (c *PlannedWorkoutUseCase) CreatePlannedWorkout(ctx context.Context, request *dto.CreatePlannedWorkoutRequest, userId int) (*model.PlannedWorkout, error) {
+	arg := repository.CreatePlannedWorkoutParams{
+		ActivityType:      request.ActivityType,
+		ScheduledAt:       request.ScheduledAt,
+		DurationInMinutes: request.DurationInMinutes,
+		Recurrence:        request.Recurrence,
+		DaysOfWeek:        request.DaysOfWeek,
+		RecurrenceUntil:   request.RecurrenceUntil,
+		Notes:             request.Notes,
+		UserId:            userId,
+	}
+	if arg.Recurrence == "" {
+		arg.Recurrence = model.RecurrenceEnumNone
+	}
+	if arg.DaysOfWeek == nil {
+		arg.DaysOfWeek = []int{}
+	}
+
+	if err := validateRecurrence(arg); err != nil {
+		return nil, err
+	}
+	if _, err := c.activityUseCase.ResolveActivityType(ctx, userId, arg.ActivityType); err != nil {
+		return nil, err
+	}
+
+	plannedWorkout, err := c.plannedWorkoutRepo.CreatePlannedWorkout(ctx, arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create planned workout")
+	}
+
+	return &plannedWorkout, nil
+}
+
+func (c *PlannedWorkoutUseCase) GetPlannedWorkouts(ctx context.Context, request *dto.GetPlannedWorkoutsRequest, userId int) ([]model.PlannedWorkout, error) {
+	plannedWorkouts, err := c.plannedWorkoutRepo.ListPlannedWorkouts(ctx, repository.ListPlannedWorkoutsParams{
+		Limit:  request.Limit,
+		Offset: request.Offset,
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get planned workouts")
+	}
+
+	return plannedWorkouts, nil
+}
+
+func (c *PlannedWorkoutUseCase) GetPlannedWorkoutById(ctx context.Context, plannedWorkoutId int, userId int) (*model.PlannedWorkout, error) {
+	plannedWorkout, err := c.plannedWorkoutRepo.GetPlannedWorkout(ctx, repository.GetAndDeletePlannedWorkoutParams{
+		Id:     plannedWorkoutId,
+		UserId: userId,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get planned workout")
+	}
+
+	return &plannedWorkout, nil
+}
+
+func (c *PlannedWorkoutUseCase) UpdatePlannedWorkout(ctx context.Context, request *dto.UpdatePlannedWorkoutRequest, plannedWorkoutId int, userId int) (*model.PlannedWorkout, error) {
+	existing, err := c.GetPlannedWorkoutById(ctx, plannedWorkoutId, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	arg := repository.UpdatePlannedWorkoutParams{
+		CreatePlannedWorkoutParams: repository.CreatePlannedWorkoutParams{
+			ActivityType:      helper.DerefGeneric(request.ActivityType, existing.ActivityType),
+			ScheduledAt:       helper.DerefGeneric(request.ScheduledAt, existing.ScheduledAt),
+			DurationInMinutes: helper.DerefGeneric(request.DurationInMinutes, existing.DurationInMinutes),
+			Recurrence:        helper.DerefGeneric(request.Recurrence, existing.Recurrence),
+			DaysOfWeek:        existing.DaysOfWeek,
+			RecurrenceUntil:   request.RecurrenceUntil.Or(existing.RecurrenceUntil),
+			Notes:             request.Notes.Or(existing.Notes),
+			UserId:            userId,
+		},
+		Id: plannedWorkoutId,
+	}
+	if request.DaysOfWeek != nil {
+		arg.DaysOfWeek = request.DaysOfWeek
+	}
+
+	if err := validateRecurrence(arg.CreatePlannedWorkoutParams); err != nil {
+		return nil, err
+	}
+	if _, err := c.activityUseCase.ResolveActivityType(ctx, userId, arg.ActivityType); err != nil {
+		return nil, err
+	}
+
+	plannedWorkout, err := c.plannedWorkoutRepo.UpdatePlannedWorkout(ctx, arg)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to update planned workout")
+	}
+
+	if err := c.pruneCompletions(ctx, plannedWorkout); err != nil {
+		return nil, err
+	}
+
+	return &plannedWorkout, nil
+}
+
+// pruneCompletions deletes the completions of occurrences a rescheduled
+// workout no longer has. Their activities are kept.
+func (c *PlannedWorkoutUseCase) pruneCompletions(ctx context.Context, plannedWorkout model.PlannedWorkout) error {
+	completions, err := c.plannedWorkoutRepo.ListPlannedWorkoutCompletions(ctx, plannedWorkout.ID)
+	if err != nil {
+		return errors.Wrap(err, "failed to get completions")
+	}
+	if len(completions) == 0 {
+		return nil
+	}
+
+	loc, err := c.userLocation(ctx, plannedWorkout.UserId)
+	if err != nil {
+		return err
+	}
+
+	var stale []time.Time
+	for _, completion := range completions {
+		if !isOccurrence(plannedWorkout, loc, completion.OccurrenceAt) {
+			stale = append(stale, completion.OccurrenceAt)
+		}
+	}
+	if len(stale) == 0 {
+		return nil
+	}
+
+	if err := c.plannedWorkoutRepo.DeleteCompletions(ctx, plannedWorkout.ID, stale); err != nil {
+		return errors.Wrap(err, "failed to delete completions")
+	}
+	return nil
+}
+
+func (c *PlannedWorkoutUseCase) DeletePlannedWorkout(ctx context.Context, plannedWorkoutId int, userId int) error {
+	return c.plannedWorkoutRepo.DeletePlannedWorkout(ctx, repository.GetAndDeletePlannedWorkoutParams{
+		Id:     plannedWorkoutId,
+		UserId: userId,
+	})
+}
+
+// CompletePlannedWorkout logs an occurrence of a planned workout as an
+// activity. The occurrence is claimed first so it cannot be completed twice.
+func (c *PlannedWorkoutUseCase) CompletePlannedWorkout(ctx context.Context, request *dto.CompletePlannedWorkoutRequest, plannedWorkoutId int, userId int) (*activityModel.Activity, []recordModel.PersonalRecord, error) {
+	plannedWorkout, err := c.GetPlannedWorkoutById(ctx, plannedWorkoutId, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	occurrenceAt := plannedWorkout.ScheduledAt
+	if request.OccurrenceAt != nil {
+		occurrenceAt = *request.OccurrenceAt
+	} else if plannedWorkout.Recurrence != model.RecurrenceEnumNone {
+		return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "occurrenceAt is required for recurring workouts")
+	}
+
+	loc, err := c.userLocation(ctx, userId)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !isOccurrence(*plannedWorkout, loc, occurrenceAt) {
+		return nil, nil, errors.Wrap(customErrors.ErrBadRequest, "occurrenceAt is not an occurrence of the planned workout")
+	}
+
+	if _, err := c.plannedWorkoutRepo.CreateCompletion(ctx, plannedWorkout.ID, occurrenceAt); err != nil {
+		return nil, nil, err
+	}
+
+	activityRequest := &activityDto.CreateAndUpdateActivityRequest{
+		ActivityType:        plannedWorkout.ActivityType,
+		DoneAt:              helper.DerefGeneric(request.DoneAt, occurrenceAt),
+		DurationInMinutes:   helper.DerefGeneric(request.DurationInMinutes, plannedWorkout.DurationInMinutes),
+		DistanceMeters:      request.DistanceMeters,
+		AvgHeartRate:        request.AvgHeartRate,
+		MaxHeartRate:        request.MaxHeartRate,
+		ElevationGainMeters: request.ElevationGainMeters,
+		Notes:               request.Notes,
+	}
+	if activityRequest.Notes == nil {
+		activityRequest.Notes = plannedWorkout.Notes
+	}
+
+	// CreateActivity only fails before the activity is stored, so releasing
+	// the occurrence cannot leave an activity behind
+	activity, records, err := c.activityUseCase.CreateActivity(ctx, activityRequest, userId)
+	if err != nil {
+		// release the occurrence so the user can try again
+		if deleteErr := c.plannedWorkoutRepo.DeleteCompletion(ctx, plannedWorkout.ID, occurrenceAt); deleteErr != nil {
+			return nil, nil, errors.Wrap(deleteErr, "failed to release planned workout occurrence")
+		}
+		return nil, nil, err
+	}
+
+	// the activity is saved and the occurrence claimed, failing now would only
+	// make the client log it twice
+	if _, err := c.plannedWorkoutRepo.SetCompletionActivity(ctx, plannedWorkout.ID, occurrenceAt, activity.ID); err != nil {
+		c.log.WithError(err).WithField("plannedWorkoutId", plannedWorkout.ID).Error("failed to link completion to activity")
+	}
+
+	return activity, records, nil
+}
+
+type completionKey struct {
+	plannedWorkoutId int
+	occurrenceAt     int64
+}
+
+// GetSchedule expands the planned workouts of a user into occurrences between
+// from and to, ordered by time.
+func (c *PlannedWorkoutUseCase) GetSchedule(ctx context.Context, from, to time.Time, userId int) ([]model.Occurrence, error) {
+	if !to.After(from) {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "to must be after from")
+	}
+	if to.Sub(from) > MAX_SCHEDULE_RANGE {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "schedule range must not exceed 92 days")
+	}
+
+	loc, err := c.userLocation(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	plannedWorkouts, err := c.plannedWorkoutRepo.ListPlannedWorkoutsInRange(ctx, userId, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get planned workouts")
+	}
+
+	completions, err := c.plannedWorkoutRepo.ListCompletions(ctx, userId, from, to)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get completions")
+	}
+
+	completed := make(map[completionKey]model.Completion, len(completions))
+	for _, completion := range completions {
+		completed[completionKey{completion.PlannedWorkoutId, completion.OccurrenceAt.UnixMicro()}] = completion
+	}
+
+	result := []model.Occurrence{}
+	for _, plannedWorkout := range plannedWorkouts {
+		for _, at := range occurrences(plannedWorkout, loc, from, to) {
+			occurrence := model.Occurrence{
+				PlannedWorkout: plannedWorkout,
+				At:             at,
+			}
+			if completion, ok := completed[completionKey{plannedWorkout.ID, at.UnixMicro()}]; ok {
+				occurrence.Completion = &completion
+			}
+			result = append(result, occurrence)
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool {
+		return result[i].At.Before(result[j].At)
+	})
+
+	return result, nil
+}
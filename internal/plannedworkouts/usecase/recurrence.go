@@ -0,0 +1,61 @@
+package usecase
+
+import (
+	"slices"
+	"time"
+
+	"fit-byte/internal/plannedworkouts/model"
+)
+
+// recursOn reports whether a recurring workout takes place on weekday.
+func recursOn(plannedWorkout model.PlannedWorkout, weekday time.Weekday) bool {
+	switch plannedWorkout.Recurrence {
+	case model.RecurrenceEnumDaily:
+		return true
+	case model.RecurrenceEnumWeekdays:
+		return weekday != time.Saturday && weekday != time.Sunday
+	case model.RecurrenceEnumWeekly:
+		return slices.Contains(plannedWorkout.DaysOfWeek, int(weekday))
+	default:
+		return false
+	}
+}
+
+// occurrences expands a planned workout into its occurrences in [from, to).
+// Recurring occurrences keep the local time of day of ScheduledAt in loc, so
+// they do not shift when daylight saving time starts or ends.
+func occurrences(plannedWorkout model.PlannedWorkout, loc *time.Location, from, to time.Time) []time.Time {
+	if plannedWorkout.Recurrence == model.RecurrenceEnumNone {
+		if !plannedWorkout.ScheduledAt.Before(from) && plannedWorkout.ScheduledAt.Before(to) {
+			return []time.Time{plannedWorkout.ScheduledAt}
+		}
+		return nil
+	}
+
+	start := plannedWorkout.ScheduledAt.In(loc)
+	day := time.Date(start.Year(), start.Month(), start.Day(), 0, 0, 0, 0, loc)
+	if localFrom := from.In(loc); localFrom.After(day) {
+		day = time.Date(localFrom.Year(), localFrom.Month(), localFrom.Day(), 0, 0, 0, 0, loc)
+	}
+
+	var result []time.Time
+	for ; day.Before(to); day = day.AddDate(0, 0, 1) {
+		at := time.Date(day.Year(), day.Month(), day.Day(), start.Hour(), start.Minute(), start.Second(), 0, loc)
+		if plannedWorkout.RecurrenceUntil != nil && at.After(*plannedWorkout.RecurrenceUntil) {
+			break
+		}
+		if at.Before(start) || at.Before(from) || !at.Before(to) {
+			continue
+		}
+		if recursOn(plannedWorkout, at.Weekday()) {
+			result = append(result, at)
+		}
+	}
+	return result
+}
+
+// isOccurrence reports whether the planned workout takes place exactly at at.
+func isOccurrence(plannedWorkout model.PlannedWorkout, loc *time.Location, at time.Time) bool {
+	found := occurrences(plannedWorkout, loc, at, at.Add(time.Second))
+	return len(found) > 0 && found[0].Equal(at)
+}
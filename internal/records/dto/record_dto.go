@@ -0,0 +1,21 @@
+package dto
+
+import (
+	activityDto "fit-byte/internal/activity/dto"
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/records/model"
+)
+
+type GetRecordsRequest struct {
+	ActivityType *activityModel.ActivityTypeEnum `query:"activityType" validate:"omitempty,activity_type"`
+}
+
+type RecordResponse struct {
+	ActivityType activityModel.ActivityTypeEnum `json:"activityType"`
+	RecordType   model.RecordTypeEnum           `json:"recordType"`
+	Value        int                            `json:"value"`
+	Unit         string                         `json:"unit"`
+	PeriodStart  *string                        `json:"periodStart"`
+	AchievedAt   string                         `json:"achievedAt"`
+	Activity     *activityDto.ActivityResponse  `json:"activity"`
+}
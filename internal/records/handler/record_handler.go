@@ -0,0 +1,53 @@
+package handler
+
+import (
+	"net/http"
+
+	"fit-byte/internal/records/dto"
+	"fit-byte/internal/records/model/converter"
+	"fit-byte/internal/records/usecase"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+type RecordHandler struct {
+	UseCase  usecase.RecordUseCase
+	Validate *validator.Validate
+}
+
+func NewRecordHandler(useCase usecase.RecordUseCase, validate *validator.Validate) *RecordHandler {
+	return &RecordHandler{
+		UseCase:  useCase,
+		Validate: validate,
+	}
+}
+
+func (c *RecordHandler) GetRecords(ctx echo.Context) error {
+	userData := ctx.Get("user").(*jwt.JWTClaim)
+
+	var request = new(dto.GetRecordsRequest)
+
+	if err := ctx.Bind(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := c.Validate.Struct(request); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	records, err := c.UseCase.GetRecords(ctx.Request().Context(), request, userData.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	response := converter.ToRecordResponseList(records)
+
+	return ctx.JSON(http.StatusOK, response)
+}
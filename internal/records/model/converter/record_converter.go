@@ -0,0 +1,41 @@
+package converter
+
+import (
+	activityConverter "fit-byte/internal/activity/model/converter"
+	"fit-byte/internal/records/dto"
+	"fit-byte/internal/records/model"
+	"fit-byte/pkg/helper"
+	"time"
+)
+
+func ToRecordResponse(record model.PersonalRecord) dto.RecordResponse {
+	var periodStart *string
+	if record.PeriodStart != nil {
+		formatted := record.PeriodStart.Format(time.DateOnly)
+		periodStart = &formatted
+	}
+
+	response := dto.RecordResponse{
+		ActivityType: record.ActivityType,
+		RecordType:   record.RecordType,
+		Value:        record.Value,
+		Unit:         record.RecordType.Unit(),
+		PeriodStart:  periodStart,
+		AchievedAt:   helper.FormatTimeToUTC(record.AchievedAt),
+	}
+	if record.Activity != nil {
+		activity := activityConverter.ToActivityResponse(*record.Activity)
+		response.Activity = &activity
+	}
+
+	return response
+}
+
+func ToRecordResponseList(records []model.PersonalRecord) []dto.RecordResponse {
+	// Ensure we always return an empty slice, not nil
+	responses := make([]dto.RecordResponse, len(records))
+	for i, record := range records {
+		responses[i] = ToRecordResponse(record)
+	}
+	return responses
+}
@@ -0,0 +1,53 @@
+package model
+
+import (
+	"fmt"
+	"time"
+
+	activityModel "fit-byte/internal/activity/model"
+)
+
+type RecordTypeEnum string
+
+const (
+	RecordTypeEnumLongestDuration   RecordTypeEnum = "LONGEST_DURATION"
+	RecordTypeEnumMostCalories      RecordTypeEnum = "MOST_CALORIES"
+	RecordTypeEnumMostWeeklyMinutes RecordTypeEnum = "MOST_WEEKLY_MINUTES"
+)
+
+func (e *RecordTypeEnum) Scan(src interface{}) error {
+	switch s := src.(type) {
+	case []byte:
+		*e = RecordTypeEnum(s)
+	case string:
+		*e = RecordTypeEnum(s)
+	default:
+		return fmt.Errorf("unsupported scan type for RecordTypeEnum: %T", src)
+	}
+	return nil
+}
+
+// Unit is the unit of a record's value.
+func (e RecordTypeEnum) Unit() string {
+	if e == RecordTypeEnumMostCalories {
+		return "kcal"
+	}
+	return "minutes"
+}
+
+// PersonalRecord is the best value of a user for one record type and activity
+// type. ActivityId is the activity that set it, and is nil once that activity
+// has been purged.
+type PersonalRecord struct {
+	ID           int
+	UserId       int
+	ActivityType activityModel.ActivityTypeEnum
+	RecordType   RecordTypeEnum
+	Value        int
+	ActivityId   *int
+	PeriodStart  *time.Time
+	AchievedAt   time.Time
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+	Activity     *activityModel.Activity
+}
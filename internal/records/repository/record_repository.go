@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"context"
+	activityModel "fit-byte/internal/activity/model"
+	"fit-byte/internal/records/model"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type RecordRepository struct {
+	pool *pgxpool.Pool
+}
+
+func NewRecordRepository(pool *pgxpool.Pool) *RecordRepository {
+	return &RecordRepository{pool: pool}
+}
+
+const recordColumns = `id, user_id, activity_type, record_type, value, activity_id, period_start, achieved_at, created_at, updated_at`
+
+func scanRecord(row pgx.Row) (model.PersonalRecord, error) {
+	var i model.PersonalRecord
+	err := row.Scan(
+		&i.ID,
+		&i.UserId,
+		&i.ActivityType,
+		&i.RecordType,
+		&i.Value,
+		&i.ActivityId,
+		&i.PeriodStart,
+		&i.AchievedAt,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+func collectRecords(rows pgx.Rows) ([]model.PersonalRecord, error) {
+	defer rows.Close()
+	var items []model.PersonalRecord
+	for rows.Next() {
+		i, err := scanRecord(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// upsertRecords compares an activity against the user's records for its type.
+// The weekly total covers the activity's week in the user's time zone. A
+// record is only replaced when it is beaten, so ties keep the older record.
+const upsertRecords = `-- name: UpsertRecords :many
+WITH
+act AS (
+  SELECT id, user_id, activity_type, done_at, duration_in_minutes, calories_burned
+  FROM activities
+  WHERE (id = $1::bigint)
+    AND (user_id = $2::bigint)
+    AND deleted_at IS NULL
+),
+week AS (
+  SELECT
+    date_trunc('week', act.done_at AT TIME ZONE u.time_zone)::date AS start,
+    u.time_zone
+  FROM act
+  JOIN users u ON u.id = act.user_id
+),
+weekly AS (
+  SELECT COALESCE(SUM(a.duration_in_minutes), 0)::int AS minutes
+  FROM activities a
+  JOIN act ON a.user_id = act.user_id AND a.activity_type = act.activity_type
+  CROSS JOIN week
+  WHERE a.deleted_at IS NULL
+    AND a.done_at >= week.start::timestamp AT TIME ZONE week.time_zone
+    AND a.done_at < (week.start + 7)::timestamp AT TIME ZONE week.time_zone
+),
+candidates AS (
+  SELECT 'LONGEST_DURATION'::enum_record_types AS record_type, act.duration_in_minutes AS value, NULL::date AS period_start FROM act
+  UNION ALL
+  SELECT 'MOST_CALORIES'::enum_record_types, act.calories_burned, NULL::date FROM act
+  UNION ALL
+  SELECT 'MOST_WEEKLY_MINUTES'::enum_record_types, weekly.minutes, week.start FROM weekly CROSS JOIN week
+)
+INSERT INTO personal_records (
+  user_id,
+  activity_type,
+  record_type,
+  value,
+  activity_id,
+  period_start,
+  achieved_at
+)
+SELECT act.user_id, act.activity_type, c.record_type, c.value, act.id, c.period_start, act.done_at
+FROM candidates c
+CROSS JOIN act
+WHERE c.value > 0
+ON CONFLICT (user_id, activity_type, record_type) DO UPDATE SET
+  value = EXCLUDED.value,
+  activity_id = EXCLUDED.activity_id,
+  period_start = EXCLUDED.period_start,
+  achieved_at = EXCLUDED.achieved_at
+WHERE personal_records.value < EXCLUDED.value
+RETURNING ` + recordColumns
+
+// UpsertRecords stores the records set by an activity and returns only those.
+func (r *RecordRepository) UpsertRecords(ctx context.Context, activityId int, userId int) ([]model.PersonalRecord, error) {
+	rows, err := r.pool.Query(ctx, upsertRecords, activityId, userId)
+	if err != nil {
+		return nil, err
+	}
+	return collectRecords(rows)
+}
+
+// recomputeRecords rebuilds the user's records for some activity types from
+// the activities outside the trash, so records drop when the activity that set
+// them is edited, trashed or purged. Only records that changed are returned.
+const recomputeRecords = `-- name: RecomputeRecords :many
+WITH
+acts AS (
+  SELECT
+    a.id,
+    a.activity_type,
+    a.done_at,
+    a.duration_in_minutes,
+    a.calories_burned,
+    date_trunc('week', a.done_at AT TIME ZONE u.time_zone)::date AS week_start
+  FROM activities a
+  JOIN users u ON u.id = a.user_id
+  WHERE (a.user_id = $1::bigint)
+    AND a.activity_type = ANY($2::varchar[])
+    AND a.deleted_at IS NULL
+),
+weeks AS (
+  SELECT activity_type, week_start, SUM(duration_in_minutes)::int AS minutes
+  FROM acts
+  GROUP BY activity_type, week_start
+),
+best AS (
+  (SELECT DISTINCT ON (activity_type)
+    activity_type, 'LONGEST_DURATION'::enum_record_types AS record_type, duration_in_minutes AS value, id AS activity_id, NULL::date AS period_start, done_at AS achieved_at
+  FROM acts
+  WHERE duration_in_minutes > 0
+  ORDER BY activity_type, duration_in_minutes DESC, done_at, id)
+  UNION ALL
+  (SELECT DISTINCT ON (activity_type)
+    activity_type, 'MOST_CALORIES'::enum_record_types, calories_burned, id, NULL::date, done_at
+  FROM acts
+  WHERE calories_burned > 0
+  ORDER BY activity_type, calories_burned DESC, done_at, id)
+  UNION ALL
+  (SELECT DISTINCT ON (a.activity_type)
+    a.activity_type, 'MOST_WEEKLY_MINUTES'::enum_record_types, w.minutes, a.id, w.week_start, a.done_at
+  FROM weeks w
+  JOIN acts a ON a.activity_type = w.activity_type AND a.week_start = w.week_start
+  WHERE w.minutes > 0
+  ORDER BY a.activity_type, w.minutes DESC, w.week_start, a.done_at DESC, a.id DESC)
+),
+removed AS (
+  DELETE FROM personal_records p
+  WHERE (p.user_id = $1::bigint)
+    AND p.activity_type = ANY($2::varchar[])
+    AND NOT EXISTS (
+      SELECT 1 FROM best b
+      WHERE b.activity_type = p.activity_type AND b.record_type = p.record_type
+    )
+)
+INSERT INTO personal_records (
+  user_id,
+  activity_type,
+  record_type,
+  value,
+  activity_id,
+  period_start,
+  achieved_at
+)
+SELECT $1::bigint, activity_type, record_type, value, activity_id, period_start, achieved_at
+FROM best
+ON CONFLICT (user_id, activity_type, record_type) DO UPDATE SET
+  value = EXCLUDED.value,
+  activity_id = EXCLUDED.activity_id,
+  period_start = EXCLUDED.period_start,
+  achieved_at = EXCLUDED.achieved_at
+WHERE (personal_records.value, personal_records.activity_id, personal_records.period_start, personal_records.achieved_at)
+  IS DISTINCT FROM (EXCLUDED.value, EXCLUDED.activity_id, EXCLUDED.period_start, EXCLUDED.achieved_at)
+RETURNING ` + recordColumns
+
+func (r *RecordRepository) RecomputeRecords(ctx context.Context, userId int, activityTypes []activityModel.ActivityTypeEnum) ([]model.PersonalRecord, error) {
+	rows, err := r.pool.Query(ctx, recomputeRecords, userId, activityTypes)
+	if err != nil {
+		return nil, err
+	}
+	return collectRecords(rows)
+}
+
+const listRecords = `-- name: ListRecords :many
+SELECT ` + recordColumns + ` FROM personal_records
+WHERE (user_id = $1::bigint)
+  AND ($2::varchar IS NULL OR activity_type = $2::varchar)
+ORDER BY activity_type, record_type
+`
+
+type ListRecordsParams struct {
+	UserId       int
+	ActivityType *activityModel.ActivityTypeEnum
+}
+
+func (r *RecordRepository) ListRecords(ctx context.Context, arg ListRecordsParams) ([]model.PersonalRecord, error) {
+	rows, err := r.pool.Query(ctx, listRecords, arg.UserId, arg.ActivityType)
+	if err != nil {
+		return nil, err
+	}
+	return collectRecords(rows)
+}
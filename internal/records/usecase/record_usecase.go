@@ -0,0 +1,92 @@
+package usecase
+
+import (
+	"context"
+
+	activityModel "fit-byte/internal/activity/model"
+	activityRepository "fit-byte/internal/activity/repository"
+	"fit-byte/internal/records/dto"
+	"fit-byte/internal/records/model"
+	"fit-byte/internal/records/repository"
+
+	"github.com/pkg/errors"
+)
+
+type RecordUseCase struct {
+	recordRepo   repository.RecordRepository
+	activityRepo activityRepository.ActivityRepository
+}
+
+func NewRecordUseCase(recordRepo repository.RecordRepository, activityRepo activityRepository.ActivityRepository) *RecordUseCase {
+	return &RecordUseCase{
+		recordRepo:   recordRepo,
+		activityRepo: activityRepo,
+	}
+}
+
+// DetectRecords updates the user's personal records with a saved activity and
+// returns the records it set.
+func (c *RecordUseCase) DetectRecords(ctx context.Context, activity activityModel.Activity) ([]model.PersonalRecord, error) {
+	records, err := c.recordRepo.UpsertRecords(ctx, activity.ID, activity.UserId)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to detect personal records")
+	}
+
+	return records, nil
+}
+
+// RecomputeRecords rebuilds the user's records for the given activity types
+// after activities were edited, trashed, restored or imported, and returns the
+// records that changed.
+func (c *RecordUseCase) RecomputeRecords(ctx context.Context, userId int, activityTypes []activityModel.ActivityTypeEnum) ([]model.PersonalRecord, error) {
+	records, err := c.recordRepo.RecomputeRecords(ctx, userId, activityTypes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to recompute personal records")
+	}
+
+	return records, nil
+}
+
+// GetRecords lists the user's current records with the activity that set each one.
+func (c *RecordUseCase) GetRecords(ctx context.Context, request *dto.GetRecordsRequest, userId int) ([]model.PersonalRecord, error) {
+	records, err := c.recordRepo.ListRecords(ctx, repository.ListRecordsParams{
+		UserId:       userId,
+		ActivityType: request.ActivityType,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get personal records")
+	}
+
+	var activityIds []int
+	for _, record := range records {
+		if record.ActivityId != nil {
+			activityIds = append(activityIds, *record.ActivityId)
+		}
+	}
+	if len(activityIds) == 0 {
+		return records, nil
+	}
+
+	activities, err := c.activityRepo.ListActivitiesByIds(ctx, userId, activityIds)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get record activities")
+	}
+	if err := c.activityRepo.AttachExercises(ctx, activities); err != nil {
+		return nil, err
+	}
+
+	activityById := make(map[int]activityModel.Activity, len(activities))
+	for _, activity := range activities {
+		activityById[activity.ID] = activity
+	}
+	for i, record := range records {
+		if record.ActivityId == nil {
+			continue
+		}
+		if activity, ok := activityById[*record.ActivityId]; ok {
+			records[i].Activity = &activity
+		}
+	}
+
+	return records, nil
+}
@@ -7,7 +7,11 @@ import (
 	"fit-byte/pkg/response"
 	"net/http"
 
+	achievementHandler "fit-byte/internal/achievements/handler"
 	activityHandler "fit-byte/internal/activity/handler"
+	goalHandler "fit-byte/internal/goals/handler"
+	plannedWorkoutHandler "fit-byte/internal/plannedworkouts/handler"
+	recordHandler "fit-byte/internal/records/handler"
 
 	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
 
@@ -15,12 +19,19 @@ import (
 )
 
 type RouteConfig struct {
-	App             *echo.Echo
-	S3Uploader      *manager.Uploader
-	ActivityHandler *activityHandler.ActivityHandler
-	UserHandler     *user_handler.UserHandler
-	FileHandler     *file_handler.FileHandler
-	Middleware      *custom_middleware.AuthConfig
+	App                   *echo.Echo
+	S3Uploader            *manager.Uploader
+	ActivityHandler       *activityHandler.ActivityHandler
+	ActivityTypeHandler   *activityHandler.ActivityTypeHandler
+	GoalHandler           *goalHandler.GoalHandler
+	RecordHandler         *recordHandler.RecordHandler
+	AchievementHandler    *achievementHandler.AchievementHandler
+	PlannedWorkoutHandler *plannedWorkoutHandler.PlannedWorkoutHandler
+	UserHandler           *user_handler.UserHandler
+	FileHandler           *file_handler.FileHandler
+	Middleware            *custom_middleware.AuthConfig
+	Idempotency           *custom_middleware.IdempotencyConfig
+	Verification          *custom_middleware.VerificationConfig
 }
 
 func (r *RouteConfig) SetupRoutes() {
@@ -39,11 +50,23 @@ func (r *RouteConfig) SetupRoutes() {
 func (r *RouteConfig) setupPublicRoutes(group *echo.Group) {
 	group.POST("/register", r.UserHandler.Register)
 	group.POST("/login", r.UserHandler.Login)
+	group.POST("/login/2fa", r.UserHandler.VerifyLoginChallenge)
+	group.POST("/token/refresh", r.UserHandler.RefreshToken)
+	group.POST("/password/forgot", r.UserHandler.ForgotPassword)
+	group.POST("/password/reset", r.UserHandler.ResetPassword)
+	group.POST("/verify-email", r.UserHandler.VerifyEmail)
 }
 
 func (r *RouteConfig) setupAuthRoutes(group *echo.Group, m echo.MiddlewareFunc) {
-	group.POST("/file", r.FileHandler.UploadFile, m)
+	group.POST("/file", r.FileHandler.UploadFile, m, r.Verification.UploadAllowed(), r.Idempotency.Idempotent())
+	group.POST("/logout", r.UserHandler.Logout, m)
+	group.POST("/logout/all", r.UserHandler.LogoutAll, m)
+	group.POST("/verify-email/resend", r.UserHandler.ResendVerificationEmail, m)
 	r.setupActivityRoute(group, m)
+	r.setupGoalRoutes(group, m)
+	group.GET("/records", r.RecordHandler.GetRecords, m)
+	group.GET("/achievements", r.AchievementHandler.GetAchievements, m)
+	r.setupPlannedWorkoutRoutes(group, m)
 	r.setupUserRoutes(group, m)
 }
 
@@ -51,12 +74,51 @@ func (r *RouteConfig) setupActivityRoute(api *echo.Group, m echo.MiddlewareFunc)
 	// user := api.Group("/activity", r.AuthMiddleware)
 	user := api.Group("/activity", m)
 	user.GET("", r.ActivityHandler.GetActivity, m)
-	user.POST("", r.ActivityHandler.CreateActivity, m)
+	user.GET("/stats", r.ActivityHandler.GetActivityStats, m)
+	user.GET("/streaks", r.ActivityHandler.GetActivityStreaks, m)
+	user.GET("/export", r.ActivityHandler.ExportActivities, m)
+	user.GET("/trash", r.ActivityHandler.GetTrash, m)
+	user.GET("/types", r.ActivityTypeHandler.GetActivityTypes, m)
+	user.POST("/types", r.ActivityTypeHandler.CreateActivityType, m)
+	user.DELETE("/types/:activityTypeId", r.ActivityTypeHandler.DeleteActivityType, m)
+	user.POST("", r.ActivityHandler.CreateActivity, m, r.Idempotency.Idempotent())
+	user.POST("/import", r.ActivityHandler.ImportActivities, m)
+	user.POST("/upload", r.ActivityHandler.UploadActivityFile, m)
+	user.GET("/:activityId", r.ActivityHandler.GetActivityById, m)
 	user.PATCH("/:activityId",r.ActivityHandler.UpdateActivity,m)
 	user.DELETE("/:activityId",r.ActivityHandler.DeleteActivity,m)
+	user.POST("/:activityId/restore", r.ActivityHandler.RestoreActivity, m)
+}
+
+func (r *RouteConfig) setupGoalRoutes(api *echo.Group, m echo.MiddlewareFunc) {
+	goals := api.Group("/goals", m)
+	goals.GET("", r.GoalHandler.GetGoals)
+	goals.GET("/suggestions", r.GoalHandler.GetGoalSuggestions)
+	goals.POST("", r.GoalHandler.CreateGoal)
+	goals.GET("/:goalId", r.GoalHandler.GetGoalById)
+	goals.PATCH("/:goalId", r.GoalHandler.UpdateGoal)
+	goals.DELETE("/:goalId", r.GoalHandler.DeleteGoal)
+}
+
+func (r *RouteConfig) setupPlannedWorkoutRoutes(api *echo.Group, m echo.MiddlewareFunc) {
+	api.GET("/schedule", r.PlannedWorkoutHandler.GetSchedule, m)
+
+	planned := api.Group("/planned-workouts", m)
+	planned.GET("", r.PlannedWorkoutHandler.GetPlannedWorkouts)
+	planned.POST("", r.PlannedWorkoutHandler.CreatePlannedWorkout)
+	planned.GET("/:plannedWorkoutId", r.PlannedWorkoutHandler.GetPlannedWorkoutById)
+	planned.PATCH("/:plannedWorkoutId", r.PlannedWorkoutHandler.UpdatePlannedWorkout)
+	planned.DELETE("/:plannedWorkoutId", r.PlannedWorkoutHandler.DeletePlannedWorkout)
+	planned.POST("/:plannedWorkoutId/complete", r.PlannedWorkoutHandler.CompletePlannedWorkout)
 }
 
 func (r *RouteConfig) setupUserRoutes(group *echo.Group, m echo.MiddlewareFunc) {
 	group.GET("/user", r.UserHandler.GetUser, m)
 	group.PATCH("/user", r.UserHandler.UpdateUser, m)
+	group.POST("/user/password", r.UserHandler.ChangePassword, m)
+	group.GET("/user/2fa", r.UserHandler.GetTwoFactorStatus, m)
+	group.POST("/user/2fa/enroll", r.UserHandler.EnrollTwoFactor, m)
+	group.POST("/user/2fa/confirm", r.UserHandler.ConfirmTwoFactor, m)
+	group.POST("/user/2fa/disable", r.UserHandler.DisableTwoFactor, m)
+	group.POST("/user/2fa/recovery-codes", r.UserHandler.RegenerateRecoveryCodes, m)
 }
@@ -1,39 +1,148 @@
 package user_dto
 
+import "time"
+
 type AuthRequestParams struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8,max=32"`
 }
 
 type AuthUser struct {
-	ID             int
-	HashedPassword string
+	ID               int
+	HashedPassword   string
+	TokenVersion     int
+	EmailVerified    bool
+	TwoFactorEnabled bool
 }
 
+// AuthResponse returns a short lived access token and the refresh token used
+// to get the next one. With two-factor authentication enabled, logging in only
+// returns a ChallengeToken that has to be answered with a code first.
 type AuthResponse struct {
-	Email string `json:"email"`
-	Token string `json:"token"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"emailVerified"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refreshToken,omitempty"`
+	TwoFactorRequired bool   `json:"twoFactorRequired,omitempty"`
+	ChallengeToken    string `json:"challengeToken,omitempty"`
+}
+
+// LoginChallengeRequest answers a login challenge with a TOTP code or a
+// recovery code.
+type LoginChallengeRequest struct {
+	ChallengeToken string `json:"challengeToken" validate:"required"`
+	Code           string `json:"code" validate:"required,max=32"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken" validate:"required"`
+}
+
+// LogoutRequest optionally revokes the session's refresh token as well.
+type LogoutRequest struct {
+	RefreshToken *string `json:"refreshToken"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword" validate:"required"`
+	NewPassword     string `json:"newPassword" validate:"required,min=8,max=32"`
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"newPassword" validate:"required,min=8,max=32"`
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" validate:"required"`
+}
+
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" validate:"required,max=32"`
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" validate:"required"`
+	Code     string `json:"code" validate:"required,max=32"`
+}
+
+// TwoFactorEnrollmentResponse is shown once, for the user to add to an
+// authenticator app.
+type TwoFactorEnrollmentResponse struct {
+	Secret     string `json:"secret"`
+	OtpauthURI string `json:"otpauthUri"`
+}
+
+type TwoFactorStatusResponse struct {
+	Enabled                bool `json:"enabled"`
+	RecoveryCodesRemaining int  `json:"recoveryCodesRemaining"`
+}
+
+// RecoveryCodesResponse is shown once. Every code can replace a TOTP code a
+// single time.
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recoveryCodes"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+// RefreshToken is a stored refresh token. Tokens issued by rotating another
+// one share its FamilyId.
+type RefreshToken struct {
+	UserId       int
+	FamilyId     string
+	UsedAt       *time.Time
+	RevokedAt    *time.Time
+	TokenVersion int
+}
+
+// TOTP is the two-factor state of a user. Secret is sealed, and pending until
+// EnabledAt is set.
+type TOTP struct {
+	Secret                 []byte
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int
+}
+
+// LoginFailure counts the recent failed attempts behind a throttle key.
+type LoginFailure struct {
+	Key          string
+	Failures     int
+	LastFailedAt time.Time
+	LockedUntil  *time.Time
 }
 
 type User struct {
-	Name       *string `json:"name"`
-	ImageURI   *string `json:"imageUri"`
-	Height     *int    `json:"height"`
-	HeightUnit *string `json:"heightUnit"`
-	Weight     *int    `json:"weight"`
-	WeightUnit *string `json:"weightUnit"`
-	Preference *string `json:"preference"`
+	Name       *string   `json:"name"`
+	ImageURI   *string   `json:"imageUri"`
+	Height     *int      `json:"height"`
+	HeightUnit *string   `json:"heightUnit"`
+	Weight     *int      `json:"weight"`
+	WeightUnit *string   `json:"weightUnit"`
+	Preference *string   `json:"preference"`
+	TimeZone   string    `json:"timeZone"`
+	UpdatedAt  time.Time `json:"-"`
 }
 
 type GetUserResponse struct {
-	Name       *string `json:"name"`
-	ImageURI   *string `json:"imageUri"`
-	Height     *int    `json:"height"`
-	HeightUnit *string `json:"heightUnit"`
-	Weight     *int    `json:"weight"`
-	WeightUnit *string `json:"weightUnit"`
-	Preference *string `json:"preference"`
-	Email      string  `json:"email"`
+	Name          *string   `json:"name"`
+	ImageURI      *string   `json:"imageUri"`
+	Height        *int      `json:"height"`
+	HeightUnit    *string   `json:"heightUnit"`
+	Weight        *int      `json:"weight"`
+	WeightUnit    *string   `json:"weightUnit"`
+	Preference    *string   `json:"preference"`
+	TimeZone      string    `json:"timeZone"`
+	Email         string    `json:"email"`
+	EmailVerified bool      `json:"emailVerified"`
+	UpdatedAt     time.Time `json:"-"`
 }
 
 type UpdateUserParams struct {
@@ -44,4 +153,5 @@ type UpdateUserParams struct {
 	Weight     *int    `json:"weight" validate:"required,min=10,max=1000"`
 	WeightUnit *string `json:"weightUnit" validate:"required,oneof=KG LBS"`
 	Preference *string `json:"preference" validate:"required,oneof=CARDIO WEIGHT"`
+	TimeZone   *string `json:"timeZone" validate:"omitempty,timezone"`
 }
@@ -0,0 +1,117 @@
+package user_handler
+
+import (
+	user_dto "fit-byte/internal/users/dto"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/response"
+	"net/http"
+
+	"github.com/labstack/echo/v4"
+	"github.com/pkg/errors"
+)
+
+func (h *UserHandler) VerifyLoginChallenge(ctx echo.Context) error {
+	var payload user_dto.LoginChallengeRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	user, err := h.UserUsecase.VerifyLoginChallenge(ctx.Request().Context(), &payload, ctx.RealIP())
+	if err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, user)
+}
+
+func (h *UserHandler) GetTwoFactorStatus(ctx echo.Context) error {
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	status, err := h.UserUsecase.GetTwoFactorStatus(ctx.Request().Context(), authUser.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, status)
+}
+
+func (h *UserHandler) EnrollTwoFactor(ctx echo.Context) error {
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	enrollment, err := h.UserUsecase.EnrollTwoFactor(ctx.Request().Context(), authUser.ID)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, enrollment)
+}
+
+func (h *UserHandler) ConfirmTwoFactor(ctx echo.Context) error {
+	var payload user_dto.TwoFactorCodeRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	codes, err := h.UserUsecase.ConfirmTwoFactor(ctx.Request().Context(), authUser.ID, &payload)
+	if err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, codes)
+}
+
+func (h *UserHandler) DisableTwoFactor(ctx echo.Context) error {
+	var payload user_dto.DisableTwoFactorRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	if err := h.UserUsecase.DisableTwoFactor(ctx.Request().Context(), authUser.ID, &payload); err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "two-factor authentication disabled",
+	})
+}
+
+func (h *UserHandler) RegenerateRecoveryCodes(ctx echo.Context) error {
+	var payload user_dto.TwoFactorCodeRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	codes, err := h.UserUsecase.RegenerateRecoveryCodes(ctx.Request().Context(), authUser.ID, &payload)
+	if err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, codes)
+}
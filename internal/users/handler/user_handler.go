@@ -4,9 +4,11 @@ import (
 	user_dto "fit-byte/internal/users/dto"
 	user_usecase "fit-byte/internal/users/usecase"
 	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/helper"
 	"fit-byte/pkg/jwt"
 	"fit-byte/pkg/response"
 	"net/http"
+	"strconv"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/labstack/echo/v4"
@@ -25,6 +27,16 @@ func NewUserHandler(validator *validator.Validate, usecase *user_usecase.UserUse
 	}
 }
 
+// writeThrottledError writes err and, when the usecase throttled the request,
+// tells the client when to try again.
+func writeThrottledError(ctx echo.Context, err error) error {
+	var throttled *user_usecase.LoginThrottledError
+	if errors.As(err, &throttled) {
+		ctx.Response().Header().Set(echo.HeaderRetryAfter, strconv.Itoa(throttled.RetryAfterSeconds()))
+	}
+	return ctx.JSON(response.WriteErrorResponse(err))
+}
+
 func (h *UserHandler) Register(ctx echo.Context) error {
 	var payload user_dto.AuthRequestParams
 
@@ -57,14 +69,163 @@ func (h *UserHandler) Login(ctx echo.Context) error {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
-	user, err := h.UserUsecase.Login(ctx.Request().Context(), &payload)
+	user, err := h.UserUsecase.Login(ctx.Request().Context(), &payload, ctx.RealIP())
 	if err != nil {
-		return ctx.JSON(response.WriteErrorResponse(err))
+		return writeThrottledError(ctx, err)
 	}
 
 	return ctx.JSON(http.StatusOK, user)
 }
 
+func (h *UserHandler) RefreshToken(ctx echo.Context) error {
+	var payload user_dto.RefreshTokenRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	tokens, err := h.UserUsecase.RefreshToken(ctx.Request().Context(), &payload)
+	if err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *UserHandler) Logout(ctx echo.Context) error {
+	var payload user_dto.LogoutRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	if err := h.UserUsecase.Logout(ctx.Request().Context(), authUser, &payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "logged out",
+	})
+}
+
+func (h *UserHandler) LogoutAll(ctx echo.Context) error {
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	if err := h.UserUsecase.LogoutAll(ctx.Request().Context(), authUser.ID); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "logged out of all sessions",
+	})
+}
+
+func (h *UserHandler) ChangePassword(ctx echo.Context) error {
+	var payload user_dto.ChangePasswordRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	tokens, err := h.UserUsecase.ChangePassword(ctx.Request().Context(), authUser.ID, &payload)
+	if err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusOK, tokens)
+}
+
+func (h *UserHandler) ForgotPassword(ctx echo.Context) error {
+	var payload user_dto.ForgotPasswordRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := h.UserUsecase.ForgotPassword(ctx.Request().Context(), &payload, ctx.RealIP()); err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusAccepted, response.BaseResponse{
+		Status:  http.StatusText(http.StatusAccepted),
+		Message: "if the email is registered, a reset link has been sent",
+	})
+}
+
+func (h *UserHandler) ResetPassword(ctx echo.Context) error {
+	var payload user_dto.ResetPasswordRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := h.UserUsecase.ResetPassword(ctx.Request().Context(), &payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "password has been reset",
+	})
+}
+
+func (h *UserHandler) VerifyEmail(ctx echo.Context) error {
+	var payload user_dto.VerifyEmailRequest
+
+	if err := ctx.Bind(&payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(customErrors.ErrBadRequest))
+	}
+
+	if err := h.Validate.Struct(&payload); err != nil {
+		err = errors.Wrap(customErrors.ErrBadRequest, err.Error())
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	if err := h.UserUsecase.VerifyEmail(ctx.Request().Context(), &payload); err != nil {
+		return ctx.JSON(response.WriteErrorResponse(err))
+	}
+
+	return ctx.JSON(http.StatusOK, response.BaseResponse{
+		Status:  http.StatusText(http.StatusOK),
+		Message: "email verified",
+	})
+}
+
+func (h *UserHandler) ResendVerificationEmail(ctx echo.Context) error {
+	authUser := ctx.Get("user").(*jwt.JWTClaim)
+	if err := h.UserUsecase.ResendVerificationEmail(ctx.Request().Context(), authUser.ID); err != nil {
+		return writeThrottledError(ctx, err)
+	}
+
+	return ctx.JSON(http.StatusAccepted, response.BaseResponse{
+		Status:  http.StatusText(http.StatusAccepted),
+		Message: "verification email sent",
+	})
+}
+
 func (h *UserHandler) GetUser(ctx echo.Context) error {
 	authUser := ctx.Get("user").(*jwt.JWTClaim)
 	user, err := h.UserUsecase.GetUser(ctx.Request().Context(), &authUser.ID)
@@ -72,6 +233,8 @@ func (h *UserHandler) GetUser(ctx echo.Context) error {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
+	ctx.Response().Header().Set(helper.HeaderETag, helper.ETag(user.UpdatedAt))
+
 	return ctx.JSON(http.StatusOK, &user)
 }
 
@@ -88,10 +251,13 @@ func (h *UserHandler) UpdateUser(ctx echo.Context) error {
 	}
 
 	authUser := ctx.Get("user").(*jwt.JWTClaim)
-	user, err := h.UserUsecase.UpdateUser(ctx.Request().Context(), &authUser.ID, &payload)
+	ifMatch := ctx.Request().Header.Get(helper.HeaderIfMatch)
+	user, err := h.UserUsecase.UpdateUser(ctx.Request().Context(), &authUser.ID, &payload, ifMatch)
 	if err != nil {
 		return ctx.JSON(response.WriteErrorResponse(err))
 	}
 
+	ctx.Response().Header().Set(helper.HeaderETag, helper.ETag(user.UpdatedAt))
+
 	return ctx.JSON(http.StatusOK, &user)
 }
@@ -0,0 +1,63 @@
+package user_repository
+
+import (
+	"context"
+	customErrors "fit-byte/pkg/custom-errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type EmailVerificationRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewEmailVerificationRepo(pool *pgxpool.Pool) *EmailVerificationRepo {
+	return &EmailVerificationRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryCreateEmailVerificationToken = `
+	INSERT INTO email_verification_tokens(user_id, token_hash, expires_at)
+	VALUES (@userId, @tokenHash, @expiresAt);`
+	queryUseEmailVerificationToken = `
+	UPDATE email_verification_tokens
+	SET used_at = NOW()
+	WHERE token_hash = @tokenHash
+		AND used_at IS NULL
+		AND expires_at > NOW()
+	RETURNING user_id;`
+)
+
+func (r *EmailVerificationRepo) CreateEmailVerificationToken(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"userId":    userId,
+		"tokenHash": tokenHash,
+		"expiresAt": expiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryCreateEmailVerificationToken, args); err != nil {
+		return errors.Wrap(err, "failed to create email verification token")
+	}
+	return nil
+}
+
+// UseEmailVerificationToken consumes a valid token and returns its user. It
+// returns customErrors.ErrNotFound when the token is unknown, expired or used.
+func (r *EmailVerificationRepo) UseEmailVerificationToken(ctx context.Context, tokenHash string) (int, error) {
+	var userId int
+	args := pgx.NamedArgs{
+		"tokenHash": tokenHash,
+	}
+
+	err := r.pool.QueryRow(ctx, queryUseEmailVerificationToken, args).Scan(&userId)
+	if err != nil {
+		return 0, customErrors.HandlePgError(err, "failed to use email verification token")
+	}
+
+	return userId, nil
+}
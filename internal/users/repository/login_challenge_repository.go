@@ -0,0 +1,86 @@
+package user_repository
+
+import (
+	"context"
+	customErrors "fit-byte/pkg/custom-errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type LoginChallengeRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginChallengeRepo(pool *pgxpool.Pool) *LoginChallengeRepo {
+	return &LoginChallengeRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryCreateLoginChallenge = `
+	INSERT INTO login_challenges(user_id, token_hash, expires_at)
+	VALUES (@userId, @tokenHash, @expiresAt);`
+	queryAttemptLoginChallenge = `
+	UPDATE login_challenges
+	SET attempts = attempts + 1
+	WHERE token_hash = @tokenHash
+		AND used_at IS NULL
+		AND expires_at > NOW()
+		AND attempts < @maxAttempts
+	RETURNING user_id;`
+	queryUseLoginChallenge = `
+	UPDATE login_challenges
+	SET used_at = NOW()
+	WHERE token_hash = @tokenHash AND used_at IS NULL
+	RETURNING user_id;`
+)
+
+func (r *LoginChallengeRepo) CreateLoginChallenge(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"userId":    userId,
+		"tokenHash": tokenHash,
+		"expiresAt": expiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryCreateLoginChallenge, args); err != nil {
+		return errors.Wrap(err, "failed to create login challenge")
+	}
+	return nil
+}
+
+// AttemptLoginChallenge counts an attempt at answering a challenge and
+// returns its user. It returns customErrors.ErrNotFound when the challenge is
+// unknown, expired, used or out of attempts.
+func (r *LoginChallengeRepo) AttemptLoginChallenge(ctx context.Context, tokenHash string, maxAttempts int) (int, error) {
+	var userId int
+	args := pgx.NamedArgs{
+		"tokenHash":   tokenHash,
+		"maxAttempts": maxAttempts,
+	}
+
+	err := r.pool.QueryRow(ctx, queryAttemptLoginChallenge, args).Scan(&userId)
+	if err != nil {
+		return 0, customErrors.HandlePgError(err, "failed to attempt login challenge")
+	}
+
+	return userId, nil
+}
+
+// UseLoginChallenge consumes a challenge once it is answered, so it cannot be
+// answered twice.
+func (r *LoginChallengeRepo) UseLoginChallenge(ctx context.Context, tokenHash string) error {
+	var userId int
+	args := pgx.NamedArgs{
+		"tokenHash": tokenHash,
+	}
+
+	err := r.pool.QueryRow(ctx, queryUseLoginChallenge, args).Scan(&userId)
+	if err != nil {
+		return customErrors.HandlePgError(err, "failed to use login challenge")
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package user_repository
+
+import (
+	"context"
+	dto "fit-byte/internal/users/dto"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type LoginFailureRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewLoginFailureRepo(pool *pgxpool.Pool) *LoginFailureRepo {
+	return &LoginFailureRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryListLoginFailures = `
+	SELECT key, failures, last_failed_at, locked_until
+	FROM login_failures
+	WHERE key = ANY(@keys);`
+	// queryReserveLoginAttempt counts an attempt as a failure before it is
+	// checked. The WHERE clause skips the update, and so returns no row, while
+	// the key is locked or backing off; it mirrors retryAt in the usecase.
+	queryReserveLoginAttempt = `
+	INSERT INTO login_failures(key, failures, last_failed_at)
+	VALUES (@key, 1, NOW())
+	ON CONFLICT (key) DO UPDATE SET
+		failures = CASE
+			WHEN login_failures.last_failed_at < @windowStart THEN 1
+			WHEN login_failures.failures + 1 >= @lockoutAfter THEN 0
+			ELSE login_failures.failures + 1
+		END,
+		last_failed_at = NOW(),
+		locked_until = CASE
+			WHEN login_failures.last_failed_at >= @windowStart
+				AND login_failures.failures + 1 >= @lockoutAfter THEN @lockedUntil
+			ELSE login_failures.locked_until
+		END
+	WHERE (login_failures.locked_until IS NULL OR login_failures.locked_until <= NOW())
+		AND (login_failures.last_failed_at < @windowStart
+			OR login_failures.failures < @freeAttempts
+			OR login_failures.last_failed_at + make_interval(secs => LEAST(
+				@baseBackoffSeconds::float8 * power(2::float8, LEAST(login_failures.failures - @freeAttempts::int, 16)),
+				@maxBackoffSeconds::float8)) <= NOW())
+	RETURNING failures;`
+	// queryReleaseLoginAttempts also lifts the lock when the released attempt
+	// is the one that set it, which is when the count was reset to zero; a
+	// rejected attempt is never reserved, so nothing else can have come since.
+	queryReleaseLoginAttempts = `
+	UPDATE login_failures
+	SET failures = GREATEST(failures - 1, 0),
+		locked_until = CASE WHEN failures = 0 THEN NULL ELSE locked_until END
+	WHERE key = ANY(@keys);`
+	queryClearLoginFailures = `
+	DELETE FROM login_failures
+	WHERE key = @key;`
+)
+
+func (r *LoginFailureRepo) ListLoginFailures(ctx context.Context, keys []string) ([]dto.LoginFailure, error) {
+	args := pgx.NamedArgs{
+		"keys": keys,
+	}
+
+	rows, err := r.pool.Query(ctx, queryListLoginFailures, args)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list login failures")
+	}
+	defer rows.Close()
+
+	var failures []dto.LoginFailure
+	for rows.Next() {
+		var i dto.LoginFailure
+		if err := rows.Scan(&i.Key, &i.Failures, &i.LastFailedAt, &i.LockedUntil); err != nil {
+			return nil, errors.Wrap(err, "failed to scan login failure")
+		}
+		failures = append(failures, i)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to list login failures")
+	}
+	return failures, nil
+}
+
+type ReserveLoginAttemptParams struct {
+	Key          string
+	FreeAttempts int
+	LockoutAfter int
+	// failures older than WindowStart are forgotten
+	WindowStart time.Time
+	LockedUntil time.Time
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+}
+
+// ReserveLoginAttempts counts an attempt against every key in one
+// transaction, so concurrent attempts cannot all pass a check made before any
+// of them was recorded. Reaching LockoutAfter locks a key until LockedUntil
+// and starts counting from zero again. It returns false, and counts nothing,
+// when any key is locked or backing off.
+func (r *LoginFailureRepo) ReserveLoginAttempts(ctx context.Context, params []ReserveLoginAttemptParams) (bool, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	for _, param := range params {
+		args := pgx.NamedArgs{
+			"key":                param.Key,
+			"freeAttempts":       param.FreeAttempts,
+			"lockoutAfter":       param.LockoutAfter,
+			"windowStart":        param.WindowStart,
+			"lockedUntil":        param.LockedUntil,
+			"baseBackoffSeconds": param.BaseBackoff.Seconds(),
+			"maxBackoffSeconds":  param.MaxBackoff.Seconds(),
+		}
+
+		var failures int
+		err := tx.QueryRow(ctx, queryReserveLoginAttempt, args).Scan(&failures)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		if err != nil {
+			return false, errors.Wrap(err, "failed to reserve login attempt")
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return false, errors.Wrap(err, "failed to reserve login attempt")
+	}
+	return true, nil
+}
+
+// ReleaseLoginAttempts takes back a reserved attempt that did not fail,
+// including the lockout it may have caused.
+func (r *LoginFailureRepo) ReleaseLoginAttempts(ctx context.Context, keys []string) error {
+	args := pgx.NamedArgs{
+		"keys": keys,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryReleaseLoginAttempts, args); err != nil {
+		return errors.Wrap(err, "failed to release login attempts")
+	}
+	return nil
+}
+
+func (r *LoginFailureRepo) ClearLoginFailures(ctx context.Context, key string) error {
+	args := pgx.NamedArgs{
+		"key": key,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryClearLoginFailures, args); err != nil {
+		return errors.Wrap(err, "failed to clear login failures")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package user_repository
+
+import (
+	"context"
+	customErrors "fit-byte/pkg/custom-errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type PasswordResetRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewPasswordResetRepo(pool *pgxpool.Pool) *PasswordResetRepo {
+	return &PasswordResetRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryCreatePasswordResetToken = `
+	INSERT INTO password_reset_tokens(user_id, token_hash, expires_at)
+	VALUES (@userId, @tokenHash, @expiresAt);`
+	queryUsePasswordResetToken = `
+	UPDATE password_reset_tokens
+	SET used_at = NOW()
+	WHERE token_hash = @tokenHash
+		AND used_at IS NULL
+		AND expires_at > NOW()
+	RETURNING user_id;`
+	queryInvalidatePasswordResetTokens = `
+	UPDATE password_reset_tokens
+	SET used_at = NOW()
+	WHERE user_id = @userId AND used_at IS NULL;`
+)
+
+func (r *PasswordResetRepo) CreatePasswordResetToken(ctx context.Context, userId int, tokenHash string, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"userId":    userId,
+		"tokenHash": tokenHash,
+		"expiresAt": expiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryCreatePasswordResetToken, args); err != nil {
+		return errors.Wrap(err, "failed to create password reset token")
+	}
+	return nil
+}
+
+// ResetPassword consumes a valid token and replaces the password of its user
+// in one transaction, so a failed update leaves the token usable. It returns
+// customErrors.ErrNotFound when the token is unknown, expired or used.
+func (r *PasswordResetRepo) ResetPassword(ctx context.Context, tokenHash string, hashedPassword string) (int, int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	var userId int
+	args := pgx.NamedArgs{
+		"tokenHash": tokenHash,
+	}
+	err = tx.QueryRow(ctx, queryUsePasswordResetToken, args).Scan(&userId)
+	if err != nil {
+		return 0, 0, customErrors.HandlePgError(err, "failed to use password reset token")
+	}
+
+	version, err := replacePassword(ctx, tx, userId, hashedPassword)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, 0, errors.Wrap(err, "failed to reset password")
+	}
+	return userId, version, nil
+}
@@ -0,0 +1,145 @@
+package user_repository
+
+import (
+	"context"
+	dto "fit-byte/internal/users/dto"
+	customErrors "fit-byte/pkg/custom-errors"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type RefreshTokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRefreshTokenRepo(pool *pgxpool.Pool) *RefreshTokenRepo {
+	return &RefreshTokenRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryCreateRefreshToken = `
+	INSERT INTO refresh_tokens(user_id, family_id, token_hash, expires_at)
+	VALUES (@userId, @familyId, @tokenHash, @expiresAt);`
+	queryUseRefreshToken = `
+	UPDATE refresh_tokens t
+	SET used_at = NOW()
+	FROM users u
+	WHERE t.token_hash = @tokenHash
+		AND u.id = t.user_id
+		AND t.used_at IS NULL
+		AND t.revoked_at IS NULL
+		AND t.expires_at > NOW()
+	RETURNING t.user_id, t.family_id, t.used_at, t.revoked_at, u.token_version;`
+	queryGetRefreshToken = `
+	SELECT t.user_id, t.family_id, t.used_at, t.revoked_at, u.token_version
+	FROM refresh_tokens t
+	JOIN users u ON u.id = t.user_id
+	WHERE t.token_hash = @tokenHash;`
+	queryRevokeRefreshTokenFamily = `
+	UPDATE refresh_tokens
+	SET revoked_at = NOW()
+	WHERE family_id = @familyId AND revoked_at IS NULL;`
+	queryRevokeUserRefreshTokens = `
+	UPDATE refresh_tokens
+	SET revoked_at = NOW()
+	WHERE user_id = @userId AND revoked_at IS NULL;`
+)
+
+func (r *RefreshTokenRepo) CreateRefreshToken(ctx context.Context, userId int, familyId, tokenHash string, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"userId":    userId,
+		"familyId":  familyId,
+		"tokenHash": tokenHash,
+		"expiresAt": expiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryCreateRefreshToken, args); err != nil {
+		return errors.Wrap(err, "failed to create refresh token")
+	}
+	return nil
+}
+
+// RotateRefreshToken marks a valid token as used and stores its replacement
+// in the same family, in one transaction so a failed insert does not burn the
+// old token. It returns customErrors.ErrNotFound when the token is unknown,
+// expired, revoked or was already used, which callers tell apart with
+// GetRefreshToken.
+func (r *RefreshTokenRepo) RotateRefreshToken(ctx context.Context, tokenHash, newTokenHash string, expiresAt time.Time) (*dto.RefreshToken, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	token, err := scanRefreshToken(tx.QueryRow(ctx, queryUseRefreshToken, pgx.NamedArgs{"tokenHash": tokenHash}))
+	if err != nil {
+		return nil, err
+	}
+
+	args := pgx.NamedArgs{
+		"userId":    token.UserId,
+		"familyId":  token.FamilyId,
+		"tokenHash": newTokenHash,
+		"expiresAt": expiresAt,
+	}
+	if _, err := tx.Exec(ctx, queryCreateRefreshToken, args); err != nil {
+		return nil, errors.Wrap(err, "failed to create refresh token")
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, errors.Wrap(err, "failed to commit transaction")
+	}
+	return token, nil
+}
+
+func (r *RefreshTokenRepo) GetRefreshToken(ctx context.Context, tokenHash string) (*dto.RefreshToken, error) {
+	args := pgx.NamedArgs{
+		"tokenHash": tokenHash,
+	}
+
+	return scanRefreshToken(r.pool.QueryRow(ctx, queryGetRefreshToken, args))
+}
+
+func scanRefreshToken(row pgx.Row) (*dto.RefreshToken, error) {
+	var token dto.RefreshToken
+
+	err := row.Scan(
+		&token.UserId,
+		&token.FamilyId,
+		&token.UsedAt,
+		&token.RevokedAt,
+		&token.TokenVersion,
+	)
+	if err != nil {
+		return nil, customErrors.HandlePgError(err, "failed to get refresh token")
+	}
+
+	return &token, nil
+}
+
+func (r *RefreshTokenRepo) RevokeRefreshTokenFamily(ctx context.Context, familyId string) error {
+	args := pgx.NamedArgs{
+		"familyId": familyId,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryRevokeRefreshTokenFamily, args); err != nil {
+		return errors.Wrap(err, "failed to revoke refresh tokens")
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepo) RevokeUserRefreshTokens(ctx context.Context, userId int) error {
+	args := pgx.NamedArgs{
+		"userId": userId,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryRevokeUserRefreshTokens, args); err != nil {
+		return errors.Wrap(err, "failed to revoke refresh tokens")
+	}
+	return nil
+}
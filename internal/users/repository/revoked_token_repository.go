@@ -0,0 +1,79 @@
+package user_repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type RevokedTokenRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewRevokedTokenRepo(pool *pgxpool.Pool) *RevokedTokenRepo {
+	return &RevokedTokenRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryRevokeToken = `
+	INSERT INTO revoked_tokens(jti, user_id, expires_at)
+	VALUES (@jti, @userId, @expiresAt)
+	ON CONFLICT (jti) DO NOTHING;`
+	queryListRevokedTokens = `
+	SELECT jti, expires_at FROM revoked_tokens
+	WHERE expires_at > NOW();`
+	queryPurgeRevokedTokens = `
+	DELETE FROM revoked_tokens
+	WHERE expires_at <= NOW();`
+)
+
+func (r *RevokedTokenRepo) RevokeToken(ctx context.Context, jti string, userId int, expiresAt time.Time) error {
+	args := pgx.NamedArgs{
+		"jti":       jti,
+		"userId":    userId,
+		"expiresAt": expiresAt,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryRevokeToken, args); err != nil {
+		return errors.Wrap(err, "failed to revoke token")
+	}
+	return nil
+}
+
+// ListRevokedTokens returns the expiry of every revoked token that has not
+// expired yet, keyed by jti.
+func (r *RevokedTokenRepo) ListRevokedTokens(ctx context.Context) (map[string]time.Time, error) {
+	rows, err := r.pool.Query(ctx, queryListRevokedTokens)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list revoked tokens")
+	}
+	defer rows.Close()
+
+	tokens := map[string]time.Time{}
+	for rows.Next() {
+		var (
+			jti       string
+			expiresAt time.Time
+		)
+		if err := rows.Scan(&jti, &expiresAt); err != nil {
+			return nil, errors.Wrap(err, "failed to scan revoked token")
+		}
+		tokens[jti] = expiresAt
+	}
+	if err := rows.Err(); err != nil {
+		return nil, errors.Wrap(err, "failed to list revoked tokens")
+	}
+	return tokens, nil
+}
+
+func (r *RevokedTokenRepo) PurgeRevokedTokens(ctx context.Context) error {
+	if _, err := r.pool.Exec(ctx, queryPurgeRevokedTokens); err != nil {
+		return errors.Wrap(err, "failed to purge revoked tokens")
+	}
+	return nil
+}
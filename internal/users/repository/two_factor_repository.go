@@ -0,0 +1,196 @@
+package user_repository
+
+import (
+	"context"
+	dto "fit-byte/internal/users/dto"
+	customErrors "fit-byte/pkg/custom-errors"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
+)
+
+type TwoFactorRepo struct {
+	pool *pgxpool.Pool
+}
+
+func NewTwoFactorRepo(pool *pgxpool.Pool) *TwoFactorRepo {
+	return &TwoFactorRepo{
+		pool: pool,
+	}
+}
+
+const (
+	queryGetTOTP = `
+	SELECT
+		u.totp_secret,
+		u.totp_enabled_at,
+		(SELECT COUNT(*) FROM totp_recovery_codes c WHERE c.user_id = u.id AND c.used_at IS NULL)
+	FROM users u
+	WHERE u.id = @userId;`
+	querySetPendingTOTPSecret = `
+	UPDATE users
+	SET totp_secret = @secret, totp_last_step = NULL
+	WHERE id = @userId AND totp_enabled_at IS NULL;`
+	queryEnableTOTP = `
+	UPDATE users
+	SET totp_enabled_at = NOW(), totp_last_step = @step
+	WHERE id = @userId AND totp_enabled_at IS NULL AND totp_secret IS NOT NULL;`
+	queryDisableTOTP = `
+	UPDATE users
+	SET totp_secret = NULL, totp_enabled_at = NULL, totp_last_step = NULL
+	WHERE id = @userId;`
+	queryUseTOTPStep = `
+	UPDATE users
+	SET totp_last_step = @step
+	WHERE id = @userId AND (totp_last_step IS NULL OR totp_last_step < @step);`
+	queryDeleteRecoveryCodes = `
+	DELETE FROM totp_recovery_codes
+	WHERE user_id = @userId;`
+	queryCreateRecoveryCodes = `
+	INSERT INTO totp_recovery_codes(user_id, code_hash)
+	SELECT @userId::bigint, unnest(@codeHashes::text[]);`
+	queryUseRecoveryCode = `
+	UPDATE totp_recovery_codes
+	SET used_at = NOW()
+	WHERE user_id = @userId AND code_hash = @codeHash AND used_at IS NULL;`
+)
+
+func (r *TwoFactorRepo) GetTOTP(ctx context.Context, userId int) (*dto.TOTP, error) {
+	var totp dto.TOTP
+	args := pgx.NamedArgs{
+		"userId": userId,
+	}
+
+	err := r.pool.QueryRow(ctx, queryGetTOTP, args).Scan(&totp.Secret, &totp.EnabledAt, &totp.RecoveryCodesRemaining)
+	if err != nil {
+		return nil, customErrors.HandlePgError(err, "failed to get totp")
+	}
+
+	return &totp, nil
+}
+
+// SetPendingTOTPSecret stores a secret that still has to be confirmed. It
+// returns customErrors.ErrConflict when two-factor authentication is enabled.
+func (r *TwoFactorRepo) SetPendingTOTPSecret(ctx context.Context, userId int, secret []byte) error {
+	args := pgx.NamedArgs{
+		"userId": userId,
+		"secret": secret,
+	}
+
+	tag, err := r.pool.Exec(ctx, querySetPendingTOTPSecret, args)
+	if err != nil {
+		return errors.Wrap(err, "failed to set totp secret")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.Wrap(customErrors.ErrConflict, "two-factor authentication is already enabled")
+	}
+	return nil
+}
+
+// EnableTOTP enables the pending secret, marking step as used, and replaces
+// the recovery codes.
+func (r *TwoFactorRepo) EnableTOTP(ctx context.Context, userId int, step int64, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	args := pgx.NamedArgs{
+		"userId": userId,
+		"step":   step,
+	}
+	tag, err := tx.Exec(ctx, queryEnableTOTP, args)
+	if err != nil {
+		return errors.Wrap(err, "failed to enable totp")
+	}
+	if tag.RowsAffected() == 0 {
+		return errors.Wrap(customErrors.ErrConflict, "two-factor authentication is already enabled")
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *TwoFactorRepo) DisableTOTP(ctx context.Context, userId int) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	args := pgx.NamedArgs{
+		"userId": userId,
+	}
+	if _, err := tx.Exec(ctx, queryDisableTOTP, args); err != nil {
+		return errors.Wrap(err, "failed to disable totp")
+	}
+	if _, err := tx.Exec(ctx, queryDeleteRecoveryCodes, args); err != nil {
+		return errors.Wrap(err, "failed to delete recovery codes")
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseTOTPStep records that a code of step was used. It returns false when a
+// code of this or a later step was used already.
+func (r *TwoFactorRepo) UseTOTPStep(ctx context.Context, userId int, step int64) (bool, error) {
+	args := pgx.NamedArgs{
+		"userId": userId,
+		"step":   step,
+	}
+
+	tag, err := r.pool.Exec(ctx, queryUseTOTPStep, args)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use totp step")
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+func (r *TwoFactorRepo) ReplaceRecoveryCodes(ctx context.Context, userId int, codeHashes []string) error {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, userId, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func replaceRecoveryCodes(ctx context.Context, tx pgx.Tx, userId int, codeHashes []string) error {
+	args := pgx.NamedArgs{
+		"userId":     userId,
+		"codeHashes": codeHashes,
+	}
+
+	if _, err := tx.Exec(ctx, queryDeleteRecoveryCodes, args); err != nil {
+		return errors.Wrap(err, "failed to delete recovery codes")
+	}
+	if _, err := tx.Exec(ctx, queryCreateRecoveryCodes, args); err != nil {
+		return errors.Wrap(err, "failed to create recovery codes")
+	}
+	return nil
+}
+
+// UseRecoveryCode consumes an unused recovery code and reports whether there
+// was one.
+func (r *TwoFactorRepo) UseRecoveryCode(ctx context.Context, userId int, codeHash string) (bool, error) {
+	args := pgx.NamedArgs{
+		"userId":   userId,
+		"codeHash": codeHash,
+	}
+
+	tag, err := r.pool.Exec(ctx, queryUseRecoveryCode, args)
+	if err != nil {
+		return false, errors.Wrap(err, "failed to use recovery code")
+	}
+	return tag.RowsAffected() == 1, nil
+}
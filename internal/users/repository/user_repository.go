@@ -4,9 +4,11 @@ import (
 	"context"
 	dto "fit-byte/internal/users/dto"
 	customErrors "fit-byte/pkg/custom-errors"
+	"time"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/pkg/errors"
 )
 
 type UserRepo struct {
@@ -20,7 +22,7 @@ func NewUserRepo(pool *pgxpool.Pool) *UserRepo {
 }
 
 const (
-	queryGetUserByEmail = "SELECT id, hashed_password FROM users WHERE email = @email;"
+	queryGetUserByEmail = "SELECT id, hashed_password, token_version, email_verified_at IS NOT NULL, totp_enabled_at IS NOT NULL FROM users WHERE email = @email;"
 	queryCreateUser     = `
 	INSERT INTO users(email, hashed_password)
 	VALUES (@email, @hashedPassword)
@@ -34,7 +36,10 @@ const (
 		height_unit,
 		weight,
 		weight_unit,
-		preference
+		preference,
+		time_zone,
+		email_verified_at IS NOT NULL,
+		updated_at
 	FROM users 
 	WHERE id = @id;`
 	queryUpdateUser = `
@@ -47,7 +52,8 @@ const (
 			t.height_unit,
 			t.weight,
 			t.weight_unit,
-			t.preference
+			t.preference,
+			t.time_zone
 		FROM (VALUES
 			(
 				@name,
@@ -56,7 +62,8 @@ const (
 				@heightUnit::enum_height_units,
 				@weight::bigint,
 				@weightUnit::enum_weight_units,
-				@preference::enum_preferences
+				@preference::enum_preferences,
+				@timeZone::varchar
 			)
 		) as t(
 			name,
@@ -65,7 +72,8 @@ const (
 			height_unit,
 			weight,
 			weight_unit,
-			preference
+			preference,
+			time_zone
 		)
 	)
 	UPDATE users
@@ -76,10 +84,12 @@ const (
 		height_unit = payload.height_unit,
 		weight = payload.weight,
 		weight_unit = payload.weight_unit,
-		preference = payload.preference
+		preference = payload.preference,
+		time_zone = COALESCE(payload.time_zone, users.time_zone)
 	FROM payload
 	WHERE
 		users.id = @id
+		AND (@expectedUpdatedAt::timestamptz IS NULL OR users.updated_at = @expectedUpdatedAt::timestamptz)
 	RETURNING
 		users.name,
 		users.image_uri,
@@ -87,7 +97,20 @@ const (
 		users.height_unit,
 		users.weight,
 		users.weight_unit,
-		users.preference;`
+		users.preference,
+		users.time_zone,
+		users.updated_at;`
+	queryGetTokenVersion       = "SELECT token_version FROM users WHERE id = @id;"
+	queryIncrementTokenVersion = `
+	UPDATE users SET token_version = token_version + 1
+	WHERE id = @id
+	RETURNING token_version;`
+	queryGetHashedPassword = "SELECT hashed_password FROM users WHERE id = @id;"
+	queryUpdatePassword    = "UPDATE users SET hashed_password = @hashedPassword WHERE id = @id;"
+	queryIsEmailVerified   = "SELECT email_verified_at IS NOT NULL FROM users WHERE id = @id;"
+	queryMarkEmailVerified = `
+	UPDATE users SET email_verified_at = NOW()
+	WHERE id = @id AND email_verified_at IS NULL;`
 )
 
 func (r *UserRepo) GetUserByEmail(ctx context.Context, email *string) (*dto.AuthUser, error) {
@@ -97,7 +120,7 @@ func (r *UserRepo) GetUserByEmail(ctx context.Context, email *string) (*dto.Auth
 		"email": &email,
 	}
 
-	err := r.pool.QueryRow(ctx, queryGetUserByEmail, args).Scan(&user.ID, &user.HashedPassword)
+	err := r.pool.QueryRow(ctx, queryGetUserByEmail, args).Scan(&user.ID, &user.HashedPassword, &user.TokenVersion, &user.EmailVerified, &user.TwoFactorEnabled)
 	if err != nil {
 		return nil, customErrors.HandlePgError(err, "failed to get user")
 	}
@@ -135,6 +158,9 @@ func (r *UserRepo) GetUserByID(ctx context.Context, id *int) (*dto.GetUserRespon
 		&user.Weight,
 		&user.WeightUnit,
 		&user.Preference,
+		&user.TimeZone,
+		&user.EmailVerified,
+		&user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -143,17 +169,21 @@ func (r *UserRepo) GetUserByID(ctx context.Context, id *int) (*dto.GetUserRespon
 	return &user, nil
 }
 
-func (r *UserRepo) UpdateUser(ctx context.Context, id *int, payload *dto.UpdateUserParams) (*dto.User, error) {
+// UpdateUser overwrites the profile. When expectedUpdatedAt is set no row is
+// updated, and pgx.ErrNoRows returned, unless the profile is still at that version.
+func (r *UserRepo) UpdateUser(ctx context.Context, id *int, payload *dto.UpdateUserParams, expectedUpdatedAt *time.Time) (*dto.User, error) {
 	var user dto.User
 	args := pgx.NamedArgs{
-		"id":         &id,
-		"name":       &payload.Name,
-		"imageUri":   &payload.ImageURI,
-		"height":     &payload.Height,
-		"heightUnit": &payload.HeightUnit,
-		"weight":     &payload.Weight,
-		"weightUnit": &payload.WeightUnit,
-		"preference": &payload.Preference,
+		"id":                &id,
+		"expectedUpdatedAt": expectedUpdatedAt,
+		"name":              &payload.Name,
+		"imageUri":          &payload.ImageURI,
+		"height":            &payload.Height,
+		"heightUnit":        &payload.HeightUnit,
+		"weight":            &payload.Weight,
+		"weightUnit":        &payload.WeightUnit,
+		"preference":        &payload.Preference,
+		"timeZone":          &payload.TimeZone,
 	}
 
 	err := r.pool.QueryRow(ctx, queryUpdateUser, args).Scan(
@@ -164,6 +194,8 @@ func (r *UserRepo) UpdateUser(ctx context.Context, id *int, payload *dto.UpdateU
 		&user.Weight,
 		&user.WeightUnit,
 		&user.Preference,
+		&user.TimeZone,
+		&user.UpdatedAt,
 	)
 	if err != nil {
 		return nil, err
@@ -171,3 +203,123 @@ func (r *UserRepo) UpdateUser(ctx context.Context, id *int, payload *dto.UpdateU
 
 	return &user, nil
 }
+
+func (r *UserRepo) GetTokenVersion(ctx context.Context, id int) (int, error) {
+	var version int
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, queryGetTokenVersion, args).Scan(&version)
+	if err != nil {
+		return 0, customErrors.HandlePgError(err, "failed to get token version")
+	}
+
+	return version, nil
+}
+
+// IncrementTokenVersion invalidates every access token issued to the user so
+// far and returns the new version. The users trigger ignores token_version,
+// so the profile ETag stays valid.
+func (r *UserRepo) IncrementTokenVersion(ctx context.Context, id int) (int, error) {
+	var version int
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, queryIncrementTokenVersion, args).Scan(&version)
+	if err != nil {
+		return 0, customErrors.HandlePgError(err, "failed to increment token version")
+	}
+
+	return version, nil
+}
+
+func (r *UserRepo) GetHashedPassword(ctx context.Context, id int) (string, error) {
+	var hashedPassword string
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, queryGetHashedPassword, args).Scan(&hashedPassword)
+	if err != nil {
+		return "", customErrors.HandlePgError(err, "failed to get password")
+	}
+
+	return hashedPassword, nil
+}
+
+// ReplacePassword stores the new password and, in the same transaction, ends
+// every session and pending reset of the user. It returns the new token
+// version.
+func (r *UserRepo) ReplacePassword(ctx context.Context, id int, hashedPassword string) (int, error) {
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to begin transaction")
+	}
+	defer tx.Rollback(ctx)
+
+	version, err := replacePassword(ctx, tx, id, hashedPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, errors.Wrap(err, "failed to replace password")
+	}
+	return version, nil
+}
+
+func replacePassword(ctx context.Context, tx pgx.Tx, id int, hashedPassword string) (int, error) {
+	args := pgx.NamedArgs{
+		"id":             id,
+		"userId":         id,
+		"hashedPassword": hashedPassword,
+	}
+
+	tag, err := tx.Exec(ctx, queryUpdatePassword, args)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to update password")
+	}
+	if tag.RowsAffected() == 0 {
+		return 0, customErrors.ErrNotFound
+	}
+
+	if _, err := tx.Exec(ctx, queryInvalidatePasswordResetTokens, args); err != nil {
+		return 0, errors.Wrap(err, "failed to invalidate password reset tokens")
+	}
+	if _, err := tx.Exec(ctx, queryRevokeUserRefreshTokens, args); err != nil {
+		return 0, errors.Wrap(err, "failed to revoke refresh tokens")
+	}
+
+	var version int
+	if err := tx.QueryRow(ctx, queryIncrementTokenVersion, args).Scan(&version); err != nil {
+		return 0, errors.Wrap(err, "failed to increment token version")
+	}
+	return version, nil
+}
+
+func (r *UserRepo) IsEmailVerified(ctx context.Context, id int) (bool, error) {
+	var verified bool
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	err := r.pool.QueryRow(ctx, queryIsEmailVerified, args).Scan(&verified)
+	if err != nil {
+		return false, customErrors.HandlePgError(err, "failed to get email verification")
+	}
+
+	return verified, nil
+}
+
+func (r *UserRepo) MarkEmailVerified(ctx context.Context, id int) error {
+	args := pgx.NamedArgs{
+		"id": id,
+	}
+
+	if _, err := r.pool.Exec(ctx, queryMarkEmailVerified, args); err != nil {
+		return errors.Wrap(err, "failed to verify email")
+	}
+	return nil
+}
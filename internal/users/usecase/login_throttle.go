@@ -0,0 +1,226 @@
+package user_usecase
+
+import (
+	"context"
+	user_dto "fit-byte/internal/users/dto"
+	user_repository "fit-byte/internal/users/repository"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/dotenv"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DEFAULT_LOGIN_LOCKOUT_THRESHOLD = 10
+	DEFAULT_LOGIN_LOCKOUT_DURATION  = 15 * time.Minute
+	// failures older than this are forgotten
+	LOGIN_FAILURE_WINDOW = time.Hour
+	// failures allowed before every further attempt has to wait
+	LOGIN_FREE_ATTEMPTS = 3
+	LOGIN_BASE_BACKOFF  = time.Second
+	LOGIN_MAX_BACKOFF   = 5 * time.Minute
+	// an IP address may be shared, so it is allowed more failures
+	LOGIN_IP_THRESHOLD_FACTOR = 5
+)
+
+// LoginThrottledError is returned while an account or IP address has to wait
+// before trying again.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("too many attempts, try again in %d seconds: %s", retryAfterSeconds(e.RetryAfter), customErrors.ErrTooManyRequests)
+}
+
+func (e *LoginThrottledError) Cause() error {
+	return customErrors.ErrTooManyRequests
+}
+
+// RetryAfterSeconds is the value of the Retry-After header.
+func (e *LoginThrottledError) RetryAfterSeconds() int {
+	return retryAfterSeconds(e.RetryAfter)
+}
+
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+// LoginThrottle counts failed logins per account and per IP address, and
+// other abusable requests per key. After LOGIN_FREE_ATTEMPTS failures each attempt has to wait
+// exponentially longer, and LOGIN_LOCKOUT_THRESHOLD failures lock the key for
+// LOGIN_LOCKOUT_DURATION. Accounts are keyed by the email as typed, so
+// unknown emails behave exactly like registered ones.
+type LoginThrottle struct {
+	LoginFailureRepo *user_repository.LoginFailureRepo
+	LockoutThreshold int
+	LockoutDuration  time.Duration
+}
+
+func NewLoginThrottle(loginFailureRepo *user_repository.LoginFailureRepo, env *dotenv.Env) *LoginThrottle {
+	lockoutThreshold, err := strconv.Atoi(env.LOGIN_LOCKOUT_THRESHOLD)
+	if err != nil || lockoutThreshold <= LOGIN_FREE_ATTEMPTS {
+		lockoutThreshold = DEFAULT_LOGIN_LOCKOUT_THRESHOLD
+	}
+	lockoutDuration, err := time.ParseDuration(env.LOGIN_LOCKOUT_DURATION)
+	if err != nil || lockoutDuration <= 0 {
+		lockoutDuration = DEFAULT_LOGIN_LOCKOUT_DURATION
+	}
+
+	return &LoginThrottle{
+		LoginFailureRepo: loginFailureRepo,
+		LockoutThreshold: lockoutThreshold,
+		LockoutDuration:  lockoutDuration,
+	}
+}
+
+func accountKey(email string) string {
+	return "account:" + strings.ToLower(email)
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
+
+// userKey counts guesses at the password of a logged in user. It is apart
+// from accountKey, so failed logins by others cannot lock the user out.
+func userKey(userId int) string {
+	return fmt.Sprintf("user:%d", userId)
+}
+
+// password reset requests are counted apart from logins
+const passwordResetKeyPrefix = "reset:"
+
+// verificationEmailKey counts the verification mails a user asked for.
+func verificationEmailKey(userId int) string {
+	return fmt.Sprintf("verify:user:%d", userId)
+}
+
+// limits returns the failures allowed without backoff and the failures that
+// lock the key out.
+func (t *LoginThrottle) limits(key string) (int, int) {
+	if strings.HasPrefix(strings.TrimPrefix(key, passwordResetKeyPrefix), "ip:") {
+		return LOGIN_FREE_ATTEMPTS * LOGIN_IP_THRESHOLD_FACTOR, t.LockoutThreshold * LOGIN_IP_THRESHOLD_FACTOR
+	}
+	return LOGIN_FREE_ATTEMPTS, t.LockoutThreshold
+}
+
+// retryAt returns when the next attempt is allowed, or the zero time if it is
+// allowed now.
+func retryAt(failure user_dto.LoginFailure, freeAttempts int, now time.Time) time.Time {
+	if failure.LockedUntil != nil && failure.LockedUntil.After(now) {
+		return *failure.LockedUntil
+	}
+	if failure.LastFailedAt.Before(now.Add(-LOGIN_FAILURE_WINDOW)) {
+		return time.Time{}
+	}
+	if failure.Failures < freeAttempts {
+		return time.Time{}
+	}
+
+	backoff := LOGIN_MAX_BACKOFF
+	if exponent := failure.Failures - freeAttempts; exponent < 16 {
+		backoff = min(LOGIN_BASE_BACKOFF<<exponent, LOGIN_MAX_BACKOFF)
+	}
+	return failure.LastFailedAt.Add(backoff)
+}
+
+// wait returns how long the keys are still backing off or locked out.
+func (t *LoginThrottle) wait(ctx context.Context, keys []string) (time.Duration, error) {
+	failures, err := t.LoginFailureRepo.ListLoginFailures(ctx, keys)
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	var wait time.Duration
+	for _, failure := range failures {
+		freeAttempts, _ := t.limits(failure.Key)
+		if at := retryAt(failure, freeAttempts, now); at.After(now) {
+			wait = max(wait, at.Sub(now))
+		}
+	}
+	return wait, nil
+}
+
+// reserve counts the attempt as failed before it is checked, and rejects it
+// while any of the keys is backing off or locked out.
+func (t *LoginThrottle) reserve(ctx context.Context, keys ...string) error {
+	now := time.Now()
+	params := make([]user_repository.ReserveLoginAttemptParams, 0, len(keys))
+	for _, key := range keys {
+		freeAttempts, lockoutThreshold := t.limits(key)
+		params = append(params, user_repository.ReserveLoginAttemptParams{
+			Key:          key,
+			FreeAttempts: freeAttempts,
+			LockoutAfter: lockoutThreshold,
+			WindowStart:  now.Add(-LOGIN_FAILURE_WINDOW),
+			LockedUntil:  now.Add(t.LockoutDuration),
+			BaseBackoff:  LOGIN_BASE_BACKOFF,
+			MaxBackoff:   LOGIN_MAX_BACKOFF,
+		})
+	}
+
+	reserved, err := t.LoginFailureRepo.ReserveLoginAttempts(ctx, params)
+	if err != nil {
+		return err
+	}
+	if reserved {
+		return nil
+	}
+
+	wait, err := t.wait(ctx, keys)
+	if err != nil {
+		return err
+	}
+	// the lock may have run out in between, the client can retry right away
+	return &LoginThrottledError{RetryAfter: max(wait, time.Second)}
+}
+
+// Reserve counts a login attempt against the account and the IP address
+// before the password is compared. A wrong password needs nothing further.
+func (t *LoginThrottle) Reserve(ctx context.Context, email, ip string) error {
+	return t.reserve(ctx, accountKey(email), ipKey(ip))
+}
+
+// Release takes back an attempt whose password was right but which still has
+// to pass the second factor.
+func (t *LoginThrottle) Release(ctx context.Context, email, ip string) error {
+	return t.LoginFailureRepo.ReleaseLoginAttempts(ctx, []string{accountKey(email), ipKey(ip)})
+}
+
+// RecordSuccess clears the failures of the account. Those of the IP address
+// are only released, or logging into one account would reset guessing at
+// others.
+func (t *LoginThrottle) RecordSuccess(ctx context.Context, email, ip string) error {
+	if err := t.LoginFailureRepo.ClearLoginFailures(ctx, accountKey(email)); err != nil {
+		return err
+	}
+	return t.LoginFailureRepo.ReleaseLoginAttempts(ctx, []string{ipKey(ip)})
+}
+
+// ReserveUser counts a guess at the current password of a logged in user.
+func (t *LoginThrottle) ReserveUser(ctx context.Context, userId int) error {
+	return t.reserve(ctx, userKey(userId))
+}
+
+// ReleaseUser takes back a guess that was right.
+func (t *LoginThrottle) ReleaseUser(ctx context.Context, userId int) error {
+	return t.LoginFailureRepo.ReleaseLoginAttempts(ctx, []string{userKey(userId)})
+}
+
+// ReservePasswordReset counts a reset request per email and per IP address.
+// Requests are never released, so mails cannot be sent faster than the
+// backoff allows.
+func (t *LoginThrottle) ReservePasswordReset(ctx context.Context, email, ip string) error {
+	return t.reserve(ctx, passwordResetKeyPrefix+accountKey(email), passwordResetKeyPrefix+ipKey(ip))
+}
+
+// ReserveVerificationEmail counts a request to resend the verification mail.
+// Like reset requests they are never released.
+func (t *LoginThrottle) ReserveVerificationEmail(ctx context.Context, userId int) error {
+	return t.reserve(ctx, verificationEmailKey(userId))
+}
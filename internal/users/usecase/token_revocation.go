@@ -0,0 +1,147 @@
+package user_usecase
+
+import (
+	"context"
+	user_repository "fit-byte/internal/users/repository"
+	"fit-byte/pkg/jwt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// TOKEN_VERSION_CACHE_TTL bounds how long a "log out everywhere" on another
+	// instance takes to reach this one.
+	TOKEN_VERSION_CACHE_TTL = 15 * time.Second
+	// REVOKED_TOKEN_REFRESH_INTERVAL bounds the same for a single logout.
+	REVOKED_TOKEN_REFRESH_INTERVAL = 15 * time.Second
+)
+
+type cachedTokenVersion struct {
+	version  int
+	loadedAt time.Time
+}
+
+// TokenRevocations keeps the revoked token ids and the users' token versions
+// in memory, so authenticating a request rarely needs the database. Revoking
+// on this instance takes effect immediately, on other instances after the
+// next refresh.
+type TokenRevocations struct {
+	RevokedTokenRepo *user_repository.RevokedTokenRepo
+	UserRepo         *user_repository.UserRepo
+
+	mu       sync.RWMutex
+	revoked  map[string]time.Time
+	versions map[int]cachedTokenVersion
+}
+
+func NewTokenRevocations(revokedTokenRepo *user_repository.RevokedTokenRepo, userRepo *user_repository.UserRepo) *TokenRevocations {
+	return &TokenRevocations{
+		RevokedTokenRepo: revokedTokenRepo,
+		UserRepo:         userRepo,
+		revoked:          map[string]time.Time{},
+		versions:         map[int]cachedTokenVersion{},
+	}
+}
+
+// Load replaces the revoked token ids with the ones stored in the database and
+// forgets the cached token versions.
+func (t *TokenRevocations) Load(ctx context.Context) error {
+	revoked, err := t.RevokedTokenRepo.ListRevokedTokens(ctx)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revoked = revoked
+	t.versions = map[int]cachedTokenVersion{}
+	return nil
+}
+
+// RunRefresh reloads the revocations and purges expired ones every interval
+// until ctx is done.
+func (t *TokenRevocations) RunRefresh(ctx context.Context, interval time.Duration, log *logrus.Logger) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := t.RevokedTokenRepo.PurgeRevokedTokens(ctx); err != nil {
+				log.WithError(err).Error("failed to purge revoked tokens")
+			}
+			if err := t.Load(ctx); err != nil {
+				log.WithError(err).Error("failed to refresh revoked tokens")
+			}
+		}
+	}
+}
+
+// IsRevoked reports whether the token was logged out or issued before the
+// user's last "log out everywhere".
+func (t *TokenRevocations) IsRevoked(ctx context.Context, claim *jwt.JWTClaim) (bool, error) {
+	t.mu.RLock()
+	_, revoked := t.revoked[claim.RegisteredClaims.ID]
+	cached, ok := t.versions[claim.ID]
+	t.mu.RUnlock()
+
+	if revoked {
+		return true, nil
+	}
+
+	version := cached.version
+	if !ok || time.Since(cached.loadedAt) >= TOKEN_VERSION_CACHE_TTL {
+		var err error
+		version, err = t.UserRepo.GetTokenVersion(ctx, claim.ID)
+		if err != nil {
+			return false, err
+		}
+		t.setVersion(claim.ID, version)
+	}
+
+	return claim.Version < version, nil
+}
+
+func (t *TokenRevocations) setVersion(userId int, version int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.versions[userId] = cachedTokenVersion{
+		version:  version,
+		loadedAt: time.Now(),
+	}
+}
+
+// Revoke rejects the token from now on until it expires.
+func (t *TokenRevocations) Revoke(ctx context.Context, claim *jwt.JWTClaim) error {
+	expiresAt, err := claim.GetExpirationTime()
+	if err != nil || expiresAt == nil {
+		return errors.New("token has no expiry")
+	}
+
+	err = t.RevokedTokenRepo.RevokeToken(ctx, claim.RegisteredClaims.ID, claim.ID, expiresAt.Time)
+	if err != nil {
+		return err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.revoked[claim.RegisteredClaims.ID] = expiresAt.Time
+	return nil
+}
+
+// RevokeAll rejects every token issued to the user so far and returns the
+// version to sign new tokens with.
+func (t *TokenRevocations) RevokeAll(ctx context.Context, userId int) (int, error) {
+	version, err := t.UserRepo.IncrementTokenVersion(ctx, userId)
+	if err != nil {
+		return 0, err
+	}
+
+	t.setVersion(userId, version)
+	return version, nil
+}
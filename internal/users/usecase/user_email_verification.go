@@ -0,0 +1,80 @@
+package user_usecase
+
+import (
+	"context"
+	user_dto "fit-byte/internal/users/dto"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/mailer"
+	"fit-byte/pkg/randtoken"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DEFAULT_EMAIL_VERIFICATION_TTL = 24 * time.Hour
+
+	EMAIL_VERIFICATION_TOKEN_BYTES = 32
+)
+
+func (u *UserUsecase) sendVerificationEmail(ctx context.Context, userId int, email string) error {
+	token, err := randtoken.Generate(EMAIL_VERIFICATION_TOKEN_BYTES)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(u.EmailVerificationTTL)
+	if err := u.EmailVerificationRepo.CreateEmailVerificationToken(ctx, userId, randtoken.Hash(token), expiresAt); err != nil {
+		return err
+	}
+
+	return u.Mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Verify your FitByte email",
+		Body:    verificationBody(u.Env.EMAIL_VERIFICATION_URL, token, u.EmailVerificationTTL),
+	})
+}
+
+func verificationBody(verificationURL, token string, ttl time.Duration) string {
+	link := token
+	if verificationURL != "" {
+		link = verificationURL + "?token=" + url.QueryEscape(token)
+	}
+
+	return fmt.Sprintf("Welcome to FitByte!\n\n"+
+		"Use the following within %s to verify your email:\n\n%s", ttl, link)
+}
+
+// VerifyEmail marks the email of the token's user as verified. Every token can
+// be used once.
+func (u *UserUsecase) VerifyEmail(ctx context.Context, payload *user_dto.VerifyEmailRequest) error {
+	userId, err := u.EmailVerificationRepo.UseEmailVerificationToken(ctx, randtoken.Hash(payload.Token))
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return errors.Wrap(customErrors.ErrBadRequest, "verification token is invalid or expired")
+	}
+	if err != nil {
+		return err
+	}
+
+	return u.UserRepo.MarkEmailVerified(ctx, userId)
+}
+
+// ResendVerificationEmail mails the user a new verification token. Requests
+// are throttled like password resets, so the endpoint cannot flood an inbox.
+func (u *UserUsecase) ResendVerificationEmail(ctx context.Context, userId int) error {
+	user, err := u.UserRepo.GetUserByID(ctx, &userId)
+	if err != nil {
+		return err
+	}
+	if user.EmailVerified {
+		return errors.Wrap(customErrors.ErrConflict, "email is already verified")
+	}
+
+	if err := u.LoginThrottle.ReserveVerificationEmail(ctx, userId); err != nil {
+		return err
+	}
+
+	return u.sendVerificationEmail(ctx, userId, user.Email)
+}
@@ -0,0 +1,149 @@
+package user_usecase
+
+import (
+	"context"
+	user_dto "fit-byte/internal/users/dto"
+	"fit-byte/pkg/bycript"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/mailer"
+	"fit-byte/pkg/randtoken"
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DEFAULT_PASSWORD_RESET_TTL = time.Hour
+
+	PASSWORD_RESET_TOKEN_BYTES = 32
+)
+
+// setPassword stores the new password and ends every session and pending
+// reset of the user. It returns the token version for new tokens.
+func (u *UserUsecase) setPassword(ctx context.Context, userId int, password string) (int, error) {
+	hashedPassword, err := bycript.HashPassword(password)
+	if err != nil {
+		return 0, err
+	}
+
+	version, err := u.UserRepo.ReplacePassword(ctx, userId, hashedPassword)
+	if err != nil {
+		return 0, err
+	}
+
+	u.Revocations.setVersion(userId, version)
+	return version, nil
+}
+
+// ChangePassword logs the user out everywhere and returns new tokens for the
+// current session.
+func (u *UserUsecase) ChangePassword(ctx context.Context, userId int, payload *user_dto.ChangePasswordRequest) (*user_dto.RefreshTokenResponse, error) {
+	if err := u.LoginThrottle.ReserveUser(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	hashedPassword, err := u.UserRepo.GetHashedPassword(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := bycript.ComparePassword(payload.CurrentPassword, hashedPassword); err != nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "current password is incorrect")
+	}
+	if err := u.LoginThrottle.ReleaseUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	if payload.NewPassword == payload.CurrentPassword {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "new password must differ from the current password")
+	}
+
+	tokenVersion, err := u.setPassword(ctx, userId, payload.NewPassword)
+	if err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := u.issueTokens(ctx, userId, tokenVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user_dto.RefreshTokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// ForgotPassword mails a reset token. Unknown emails are silently ignored, and
+// the token is created and mailed after returning, so neither the response
+// nor its timing reveals which emails are registered.
+func (u *UserUsecase) ForgotPassword(ctx context.Context, payload *user_dto.ForgotPasswordRequest, ip string) error {
+	if err := u.LoginThrottle.ReservePasswordReset(ctx, payload.Email, ip); err != nil {
+		return err
+	}
+
+	user, err := u.UserRepo.GetUserByEmail(ctx, &payload.Email)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	go func() {
+		if err := u.sendPasswordReset(context.WithoutCancel(ctx), user.ID, payload.Email); err != nil {
+			u.Log.WithError(err).WithField("userId", user.ID).Error("failed to send password reset email")
+		}
+	}()
+	return nil
+}
+
+func (u *UserUsecase) sendPasswordReset(ctx context.Context, userId int, email string) error {
+	token, err := randtoken.Generate(PASSWORD_RESET_TOKEN_BYTES)
+	if err != nil {
+		return err
+	}
+
+	expiresAt := time.Now().Add(u.PasswordResetTTL)
+	if err := u.PasswordResetRepo.CreatePasswordResetToken(ctx, userId, randtoken.Hash(token), expiresAt); err != nil {
+		return err
+	}
+
+	return u.Mailer.Send(ctx, mailer.Message{
+		To:      email,
+		Subject: "Reset your FitByte password",
+		Body:    passwordResetBody(u.Env.PASSWORD_RESET_URL, token, u.PasswordResetTTL),
+	})
+}
+
+func passwordResetBody(resetURL, token string, ttl time.Duration) string {
+	link := token
+	if resetURL != "" {
+		link = resetURL + "?token=" + url.QueryEscape(token)
+	}
+
+	return fmt.Sprintf("Someone asked to reset the password of your FitByte account.\n\n"+
+		"Use the following to choose a new password within %s:\n\n%s\n\n"+
+		"If this was not you, you can ignore this email.", ttl, link)
+}
+
+// ResetPassword sets a new password with a token from ForgotPassword. Every
+// token can be used once.
+func (u *UserUsecase) ResetPassword(ctx context.Context, payload *user_dto.ResetPasswordRequest) error {
+	hashedPassword, err := bycript.HashPassword(payload.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	userId, version, err := u.PasswordResetRepo.ResetPassword(ctx, randtoken.Hash(payload.Token), hashedPassword)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return errors.Wrap(customErrors.ErrBadRequest, "reset token is invalid or expired")
+	}
+	if err != nil {
+		return err
+	}
+
+	u.Revocations.setVersion(userId, version)
+	return nil
+}
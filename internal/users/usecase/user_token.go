@@ -0,0 +1,130 @@
+package user_usecase
+
+import (
+	"context"
+	user_dto "fit-byte/internal/users/dto"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/randtoken"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DEFAULT_ACCESS_TOKEN_TTL  = 15 * time.Minute
+	DEFAULT_REFRESH_TOKEN_TTL = 30 * 24 * time.Hour
+
+	REFRESH_TOKEN_BYTES = 32
+	TOKEN_FAMILY_BYTES  = 16
+)
+
+// issueTokens starts a new refresh token family, one per login.
+func (u *UserUsecase) issueTokens(ctx context.Context, userId int, tokenVersion int) (string, string, error) {
+	familyId, err := randtoken.Generate(TOKEN_FAMILY_BYTES)
+	if err != nil {
+		return "", "", err
+	}
+
+	return u.issueTokensInFamily(ctx, userId, tokenVersion, familyId)
+}
+
+func (u *UserUsecase) issueTokensInFamily(ctx context.Context, userId int, tokenVersion int, familyId string) (string, string, error) {
+	token, err := jwt.CreateToken(userId, tokenVersion, u.Env.JWT_SECRET, u.AccessTokenTTL)
+	if err != nil {
+		return "", "", err
+	}
+
+	refreshToken, err := randtoken.Generate(REFRESH_TOKEN_BYTES)
+	if err != nil {
+		return "", "", err
+	}
+
+	expiresAt := time.Now().Add(u.RefreshTokenTTL)
+	err = u.RefreshTokenRepo.CreateRefreshToken(ctx, userId, familyId, randtoken.Hash(refreshToken), expiresAt)
+	if err != nil {
+		return "", "", err
+	}
+
+	return token, refreshToken, nil
+}
+
+// RefreshToken exchanges a refresh token for a new access and refresh token.
+// Every refresh token can be used once. Presenting one that was already
+// rotated means it leaked, so its whole family is revoked and the user has to
+// log in again.
+func (u *UserUsecase) RefreshToken(ctx context.Context, payload *user_dto.RefreshTokenRequest) (*user_dto.RefreshTokenResponse, error) {
+	tokenHash := randtoken.Hash(payload.RefreshToken)
+
+	refreshToken, err := randtoken.Generate(REFRESH_TOKEN_BYTES)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(u.RefreshTokenTTL)
+	stored, err := u.RefreshTokenRepo.RotateRefreshToken(ctx, tokenHash, randtoken.Hash(refreshToken), expiresAt)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return nil, u.rejectRefreshToken(ctx, tokenHash)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	token, err := jwt.CreateToken(stored.UserId, stored.TokenVersion, u.Env.JWT_SECRET, u.AccessTokenTTL)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user_dto.RefreshTokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+func (u *UserUsecase) rejectRefreshToken(ctx context.Context, tokenHash string) error {
+	stored, err := u.RefreshTokenRepo.GetRefreshToken(ctx, tokenHash)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return errors.Wrap(customErrors.ErrUnauthorized, "invalid refresh token")
+	}
+	if err != nil {
+		return err
+	}
+
+	if stored.UsedAt != nil && stored.RevokedAt == nil {
+		if err := u.RefreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyId); err != nil {
+			return err
+		}
+		return errors.Wrap(customErrors.ErrUnauthorized, "refresh token reuse detected")
+	}
+
+	return errors.Wrap(customErrors.ErrUnauthorized, "refresh token expired or revoked")
+}
+
+// Logout revokes the access token of the request and, when given, the family
+// of its refresh token.
+func (u *UserUsecase) Logout(ctx context.Context, claim *jwt.JWTClaim, payload *user_dto.LogoutRequest) error {
+	if payload.RefreshToken != nil {
+		stored, err := u.RefreshTokenRepo.GetRefreshToken(ctx, randtoken.Hash(*payload.RefreshToken))
+		if err != nil && !errors.Is(err, customErrors.ErrNotFound) {
+			return err
+		}
+		// a refresh token of another user is ignored rather than revoked
+		if err == nil && stored.UserId == claim.ID {
+			if err := u.RefreshTokenRepo.RevokeRefreshTokenFamily(ctx, stored.FamilyId); err != nil {
+				return err
+			}
+		}
+	}
+
+	return u.Revocations.Revoke(ctx, claim)
+}
+
+// LogoutAll revokes every access and refresh token of the user.
+func (u *UserUsecase) LogoutAll(ctx context.Context, userId int) error {
+	if err := u.RefreshTokenRepo.RevokeUserRefreshTokens(ctx, userId); err != nil {
+		return err
+	}
+
+	_, err := u.Revocations.RevokeAll(ctx, userId)
+	return err
+}
@@ -0,0 +1,344 @@
+package user_usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base32"
+	user_dto "fit-byte/internal/users/dto"
+	"fit-byte/pkg/bycript"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/randtoken"
+	"fit-byte/pkg/totp"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	TOTP_ISSUER = "FitByte"
+
+	LOGIN_CHALLENGE_TTL          = 5 * time.Minute
+	LOGIN_CHALLENGE_MAX_ATTEMPTS = 5
+	LOGIN_CHALLENGE_BYTES        = 32
+
+	RECOVERY_CODE_COUNT = 10
+	RECOVERY_CODE_BYTES = 10
+)
+
+var recoveryCodeEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// errTwoFactorNotConfigured is returned when TOTP secrets have to be sealed or
+// opened but TOTP_ENCRYPTION_KEY is not set.
+var errTwoFactorNotConfigured = errors.New("two-factor authentication is not configured")
+
+// generateRecoveryCodes returns the codes to show the user and the hashes to
+// store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, RECOVERY_CODE_COUNT)
+	hashes := make([]string, RECOVERY_CODE_COUNT)
+	for i := range codes {
+		b := make([]byte, RECOVERY_CODE_BYTES)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+		code := strings.ToLower(recoveryCodeEncoding.EncodeToString(b))
+		codes[i] = code[:8] + "-" + code[8:]
+		hashes[i] = randtoken.Hash(code)
+	}
+	return codes, hashes, nil
+}
+
+// normalizeRecoveryCode accepts a recovery code in any case, with or without
+// its dash.
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(code))
+}
+
+func (u *UserUsecase) openTOTPSecret(sealed []byte) (string, error) {
+	if u.SecretBox == nil {
+		return "", errTwoFactorNotConfigured
+	}
+	secret, err := u.SecretBox.Open(sealed)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to decrypt totp secret")
+	}
+	return string(secret), nil
+}
+
+// verifyTOTP accepts each code of the secret once.
+func (u *UserUsecase) verifyTOTP(ctx context.Context, userId int, sealed []byte, code string) (bool, error) {
+	secret, err := u.openTOTPSecret(sealed)
+	if err != nil {
+		return false, err
+	}
+
+	step, ok := totp.Validate(secret, strings.TrimSpace(code), time.Now())
+	if !ok {
+		return false, nil
+	}
+	return u.TwoFactorRepo.UseTOTPStep(ctx, userId, step)
+}
+
+// verifySecondFactor accepts a TOTP code or an unused recovery code.
+func (u *UserUsecase) verifySecondFactor(ctx context.Context, userId int, state *user_dto.TOTP, code string) (bool, error) {
+	if len(strings.TrimSpace(code)) == totp.Digits {
+		return u.verifyTOTP(ctx, userId, state.Secret, code)
+	}
+	return u.TwoFactorRepo.UseRecoveryCode(ctx, userId, randtoken.Hash(normalizeRecoveryCode(code)))
+}
+
+func (u *UserUsecase) getEnabledTOTP(ctx context.Context, userId int) (*user_dto.TOTP, error) {
+	state, err := u.TwoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if state.EnabledAt == nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "two-factor authentication is not enabled")
+	}
+	return state, nil
+}
+
+func (u *UserUsecase) GetTwoFactorStatus(ctx context.Context, userId int) (*user_dto.TwoFactorStatusResponse, error) {
+	state, err := u.TwoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user_dto.TwoFactorStatusResponse{
+		Enabled:                state.EnabledAt != nil,
+		RecoveryCodesRemaining: state.RecoveryCodesRemaining,
+	}, nil
+}
+
+// EnrollTwoFactor starts enrollment with a new secret. Two-factor
+// authentication is only enabled once ConfirmTwoFactor gets a code of it, so
+// enrolling again simply replaces an unconfirmed secret.
+func (u *UserUsecase) EnrollTwoFactor(ctx context.Context, userId int) (*user_dto.TwoFactorEnrollmentResponse, error) {
+	if u.SecretBox == nil {
+		return nil, errTwoFactorNotConfigured
+	}
+
+	user, err := u.UserRepo.GetUserByID(ctx, &userId)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := u.SecretBox.Seal([]byte(secret))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to encrypt totp secret")
+	}
+
+	if err := u.TwoFactorRepo.SetPendingTOTPSecret(ctx, userId, sealed); err != nil {
+		return nil, err
+	}
+
+	return &user_dto.TwoFactorEnrollmentResponse{
+		Secret:     secret,
+		OtpauthURI: totp.URI(TOTP_ISSUER, user.Email, secret),
+	}, nil
+}
+
+// ConfirmTwoFactor enables two-factor authentication with a first code of the
+// enrolled secret and returns the recovery codes.
+func (u *UserUsecase) ConfirmTwoFactor(ctx context.Context, userId int, payload *user_dto.TwoFactorCodeRequest) (*user_dto.RecoveryCodesResponse, error) {
+	state, err := u.TwoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if state.EnabledAt != nil {
+		return nil, errors.Wrap(customErrors.ErrConflict, "two-factor authentication is already enabled")
+	}
+	if state.Secret == nil {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "two-factor authentication has not been enrolled")
+	}
+
+	secret, err := u.openTOTPSecret(state.Secret)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.LoginThrottle.ReserveUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	step, ok := totp.Validate(secret, strings.TrimSpace(payload.Code), time.Now())
+	if !ok {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid code")
+	}
+	if err := u.LoginThrottle.ReleaseUser(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.TwoFactorRepo.EnableTOTP(ctx, userId, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &user_dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// DisableTwoFactor needs the password as well as a code, so a stolen session
+// alone cannot turn it off. Guesses at either count against the same
+// per-account throttle as ChangePassword.
+func (u *UserUsecase) DisableTwoFactor(ctx context.Context, userId int, payload *user_dto.DisableTwoFactorRequest) error {
+	if err := u.LoginThrottle.ReserveUser(ctx, userId); err != nil {
+		return err
+	}
+
+	hashedPassword, err := u.UserRepo.GetHashedPassword(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if err := bycript.ComparePassword(payload.Password, hashedPassword); err != nil {
+		return errors.Wrap(customErrors.ErrBadRequest, "password is incorrect")
+	}
+
+	state, err := u.getEnabledTOTP(ctx, userId)
+	if err != nil {
+		return err
+	}
+	ok, err := u.verifySecondFactor(ctx, userId, state, payload.Code)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.Wrap(customErrors.ErrBadRequest, "invalid code")
+	}
+	if err := u.LoginThrottle.ReleaseUser(ctx, userId); err != nil {
+		return err
+	}
+
+	return u.TwoFactorRepo.DisableTOTP(ctx, userId)
+}
+
+// RegenerateRecoveryCodes replaces every recovery code. It takes a TOTP code,
+// not a recovery code.
+func (u *UserUsecase) RegenerateRecoveryCodes(ctx context.Context, userId int, payload *user_dto.TwoFactorCodeRequest) (*user_dto.RecoveryCodesResponse, error) {
+	state, err := u.getEnabledTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.LoginThrottle.ReserveUser(ctx, userId); err != nil {
+		return nil, err
+	}
+	ok, err := u.verifyTOTP(ctx, userId, state.Secret, payload.Code)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Wrap(customErrors.ErrBadRequest, "invalid code")
+	}
+	if err := u.LoginThrottle.ReleaseUser(ctx, userId); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := u.TwoFactorRepo.ReplaceRecoveryCodes(ctx, userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return &user_dto.RecoveryCodesResponse{
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// createLoginChallenge is returned by Login instead of tokens when the user
+// has two-factor authentication enabled.
+func (u *UserUsecase) createLoginChallenge(ctx context.Context, user *user_dto.AuthUser, email string) (*user_dto.AuthResponse, error) {
+	token, err := randtoken.Generate(LOGIN_CHALLENGE_BYTES)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(LOGIN_CHALLENGE_TTL)
+	if err := u.LoginChallengeRepo.CreateLoginChallenge(ctx, user.ID, randtoken.Hash(token), expiresAt); err != nil {
+		return nil, err
+	}
+
+	return &user_dto.AuthResponse{
+		Email:             email,
+		EmailVerified:     user.EmailVerified,
+		TwoFactorRequired: true,
+		ChallengeToken:    token,
+	}, nil
+}
+
+// VerifyLoginChallenge completes a login with a TOTP or recovery code. A
+// challenge allows LOGIN_CHALLENGE_MAX_ATTEMPTS attempts, after which the
+// user has to log in with the password again. Codes are throttled like
+// passwords, and only a right code clears the failures of the account.
+func (u *UserUsecase) VerifyLoginChallenge(ctx context.Context, payload *user_dto.LoginChallengeRequest, ip string) (*user_dto.AuthResponse, error) {
+	tokenHash := randtoken.Hash(payload.ChallengeToken)
+
+	userId, err := u.LoginChallengeRepo.AttemptLoginChallenge(ctx, tokenHash, LOGIN_CHALLENGE_MAX_ATTEMPTS)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return nil, errors.Wrap(customErrors.ErrUnauthorized, "login challenge is invalid or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	state, err := u.TwoFactorRepo.GetTOTP(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+	if state.EnabledAt == nil {
+		return nil, errors.Wrap(customErrors.ErrUnauthorized, "login challenge is invalid or expired")
+	}
+
+	user, err := u.UserRepo.GetUserByID(ctx, &userId)
+	if err != nil {
+		return nil, err
+	}
+	if err := u.LoginThrottle.Reserve(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	ok, err := u.verifySecondFactor(ctx, userId, state, payload.Code)
+	if err != nil {
+		return nil, err
+	}
+	// the attempt has already been counted as a failure
+	if !ok {
+		return nil, errors.Wrap(customErrors.ErrUnauthorized, "invalid code")
+	}
+	if err := u.LoginThrottle.RecordSuccess(ctx, user.Email, ip); err != nil {
+		return nil, err
+	}
+
+	err = u.LoginChallengeRepo.UseLoginChallenge(ctx, tokenHash)
+	if errors.Is(err, customErrors.ErrNotFound) {
+		return nil, errors.Wrap(customErrors.ErrUnauthorized, "login challenge is invalid or expired")
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	tokenVersion, err := u.UserRepo.GetTokenVersion(ctx, userId)
+	if err != nil {
+		return nil, err
+	}
+
+	token, refreshToken, err := u.issueTokens(ctx, userId, tokenVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return &user_dto.AuthResponse{
+		Email:         user.Email,
+		EmailVerified: user.EmailVerified,
+		Token:         token,
+		RefreshToken:  refreshToken,
+	}, nil
+}
@@ -5,19 +5,72 @@ import (
 	user_dto "fit-byte/internal/users/dto"
 	user_repository "fit-byte/internal/users/repository"
 	"fit-byte/pkg/bycript"
+	customErrors "fit-byte/pkg/custom-errors"
 	"fit-byte/pkg/dotenv"
-	"fit-byte/pkg/jwt"
+	"fit-byte/pkg/helper"
+	"fit-byte/pkg/mailer"
+	"fit-byte/pkg/secretbox"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/bcrypt"
 )
 
 type UserUsecase struct {
-	UserRepo *user_repository.UserRepo
-	Env      *dotenv.Env
+	UserRepo              *user_repository.UserRepo
+	RefreshTokenRepo      *user_repository.RefreshTokenRepo
+	PasswordResetRepo     *user_repository.PasswordResetRepo
+	EmailVerificationRepo *user_repository.EmailVerificationRepo
+	TwoFactorRepo         *user_repository.TwoFactorRepo
+	LoginChallengeRepo    *user_repository.LoginChallengeRepo
+	Revocations           *TokenRevocations
+	LoginThrottle         *LoginThrottle
+	SecretBox             *secretbox.Box
+	Mailer                mailer.Mailer
+	Env                   *dotenv.Env
+	Log                   *logrus.Logger
+	AccessTokenTTL        time.Duration
+	RefreshTokenTTL       time.Duration
+	PasswordResetTTL      time.Duration
+	EmailVerificationTTL  time.Duration
 }
 
-func NewUserUsecase(repo *user_repository.UserRepo, env *dotenv.Env) *UserUsecase {
+func NewUserUsecase(repo *user_repository.UserRepo, refreshTokenRepo *user_repository.RefreshTokenRepo, passwordResetRepo *user_repository.PasswordResetRepo, emailVerificationRepo *user_repository.EmailVerificationRepo, twoFactorRepo *user_repository.TwoFactorRepo, loginChallengeRepo *user_repository.LoginChallengeRepo, revocations *TokenRevocations, loginThrottle *LoginThrottle, secretBox *secretbox.Box, mailer mailer.Mailer, env *dotenv.Env, log *logrus.Logger) *UserUsecase {
+	accessTokenTTL, err := time.ParseDuration(env.ACCESS_TOKEN_TTL)
+	if err != nil || accessTokenTTL <= 0 {
+		accessTokenTTL = DEFAULT_ACCESS_TOKEN_TTL
+	}
+	refreshTokenTTL, err := time.ParseDuration(env.REFRESH_TOKEN_TTL)
+	if err != nil || refreshTokenTTL <= 0 {
+		refreshTokenTTL = DEFAULT_REFRESH_TOKEN_TTL
+	}
+	passwordResetTTL, err := time.ParseDuration(env.PASSWORD_RESET_TTL)
+	if err != nil || passwordResetTTL <= 0 {
+		passwordResetTTL = DEFAULT_PASSWORD_RESET_TTL
+	}
+	emailVerificationTTL, err := time.ParseDuration(env.EMAIL_VERIFICATION_TTL)
+	if err != nil || emailVerificationTTL <= 0 {
+		emailVerificationTTL = DEFAULT_EMAIL_VERIFICATION_TTL
+	}
+
 	return &UserUsecase{
-		UserRepo: repo,
-		Env:      env,
+		UserRepo:              repo,
+		RefreshTokenRepo:      refreshTokenRepo,
+		PasswordResetRepo:     passwordResetRepo,
+		EmailVerificationRepo: emailVerificationRepo,
+		TwoFactorRepo:         twoFactorRepo,
+		LoginChallengeRepo:    loginChallengeRepo,
+		Revocations:           revocations,
+		LoginThrottle:         loginThrottle,
+		SecretBox:             secretBox,
+		Mailer:                mailer,
+		Env:                   env,
+		Log:                   log,
+		AccessTokenTTL:        accessTokenTTL,
+		RefreshTokenTTL:       refreshTokenTTL,
+		PasswordResetTTL:      passwordResetTTL,
+		EmailVerificationTTL:  emailVerificationTTL,
 	}
 }
 
@@ -33,41 +86,82 @@ func (u *UserUsecase) Register(ctx context.Context, payload *user_dto.AuthReques
 		return nil, err
 	}
 
-	// Generate Token
-	token, err := jwt.CreateToken(*id, u.Env.JWT_SECRET)
+	// Generate Tokens
+	token, refreshToken, err := u.issueTokens(ctx, *id, 0)
 	if err != nil {
 		return nil, err
 	}
 
+	// the account works without a verification email, which can be resent
+	if err := u.sendVerificationEmail(ctx, *id, payload.Email); err != nil {
+		u.Log.WithError(err).WithField("userId", *id).Error("failed to send verification email")
+	}
+
 	authResponse := user_dto.AuthResponse{
-		Email: payload.Email,
-		Token: token,
+		Email:         payload.Email,
+		EmailVerified: false,
+		Token:         token,
+		RefreshToken:  refreshToken,
 	}
 	return &authResponse, nil
 }
 
-func (u *UserUsecase) Login(ctx context.Context, payload *user_dto.AuthRequestParams) (*user_dto.AuthResponse, error) {
+// dummyHashedPassword is compared against when the email is unknown, so the
+// response takes as long as for a wrong password.
+const dummyHashedPassword = "$2a$10$ikF0k/3MeCUgYy5M0Somt.BGA.IkNvdPujDyqqeEdS.urbW7uN01m"
+
+// Login answers an unknown email and a wrong password the same way, and is
+// throttled per account and per IP address. Users with two-factor
+// authentication get a challenge for VerifyLoginChallenge instead of tokens.
+func (u *UserUsecase) Login(ctx context.Context, payload *user_dto.AuthRequestParams, ip string) (*user_dto.AuthResponse, error) {
 	var authResponse user_dto.AuthResponse
 
+	if err := u.LoginThrottle.Reserve(ctx, payload.Email, ip); err != nil {
+		return nil, err
+	}
+
 	user, err := u.UserRepo.GetUserByEmail(ctx, &payload.Email)
-	if err != nil {
+	if err != nil && !errors.Is(err, customErrors.ErrNotFound) {
 		return nil, err
 	}
 
 	// Compare password
-	err = bycript.ComparePassword(payload.Password, user.HashedPassword)
+	hashedPassword := dummyHashedPassword
+	if user != nil {
+		hashedPassword = user.HashedPassword
+	}
+	err = bycript.ComparePassword(payload.Password, hashedPassword)
+	// the attempt has already been counted as a failure
+	if user == nil || errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return nil, errors.Wrap(customErrors.ErrUnauthorized, "invalid email or password")
+	}
 	if err != nil {
+		return nil, errors.Wrap(err, "failed to compare password")
+	}
+
+	// the failures of the account are only cleared once the second factor
+	// has passed too
+	if user.TwoFactorEnabled {
+		if err := u.LoginThrottle.Release(ctx, payload.Email, ip); err != nil {
+			return nil, err
+		}
+		return u.createLoginChallenge(ctx, user, payload.Email)
+	}
+
+	if err := u.LoginThrottle.RecordSuccess(ctx, payload.Email, ip); err != nil {
 		return nil, err
 	}
 
-	// Generate Token
-	token, err := jwt.CreateToken(user.ID, u.Env.JWT_SECRET)
+	// Generate Tokens
+	token, refreshToken, err := u.issueTokens(ctx, user.ID, user.TokenVersion)
 	if err != nil {
 		return nil, err
 	}
 
 	authResponse.Email = payload.Email
+	authResponse.EmailVerified = user.EmailVerified
 	authResponse.Token = token
+	authResponse.RefreshToken = refreshToken
 	return &authResponse, nil
 }
 
@@ -79,9 +173,26 @@ func (u *UserUsecase) GetUser(ctx context.Context, id *int) (*user_dto.GetUserRe
 	return user, nil
 }
 
-func (u *UserUsecase) UpdateUser(ctx context.Context, id *int, payload *user_dto.UpdateUserParams) (*user_dto.User, error) {
-	user, err := u.UserRepo.UpdateUser(ctx, id, payload)
+// UpdateUser updates the profile. When ifMatch is set the update only succeeds
+// if it matches the profile's current ETag.
+func (u *UserUsecase) UpdateUser(ctx context.Context, id *int, payload *user_dto.UpdateUserParams, ifMatch string) (*user_dto.User, error) {
+	var expectedUpdatedAt *time.Time
+	if ifMatch != "" {
+		current, err := u.UserRepo.GetUserByID(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if !helper.MatchesETag(ifMatch, helper.ETag(current.UpdatedAt)) {
+			return nil, errors.Wrap(customErrors.ErrPreconditionFailed, "user has been modified")
+		}
+		expectedUpdatedAt = &current.UpdatedAt
+	}
+
+	user, err := u.UserRepo.UpdateUser(ctx, id, payload, expectedUpdatedAt)
 	if err != nil {
+		if expectedUpdatedAt != nil && errors.Is(err, customErrors.ErrNotFound) {
+			return nil, errors.Wrap(customErrors.ErrPreconditionFailed, "user has been modified")
+		}
 		return nil, err
 	}
 	return user, nil
@@ -0,0 +1,71 @@
+package user_usecase
+
+import (
+	"context"
+	user_repository "fit-byte/internal/users/repository"
+	customErrors "fit-byte/pkg/custom-errors"
+	"fit-byte/pkg/dotenv"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	DEFAULT_UNVERIFIED_ACTIVITY_LIMIT = 10
+)
+
+// VerificationPolicy limits what accounts with an unverified email can do.
+// They can log in and use the app, but may not upload files unless
+// UNVERIFIED_UPLOADS_ALLOWED is true and may keep at most
+// UNVERIFIED_ACTIVITY_LIMIT activities. A negative limit disables it.
+type VerificationPolicy struct {
+	UserRepo                *user_repository.UserRepo
+	MaxUnverifiedActivities int
+	AllowUploads            bool
+}
+
+func NewVerificationPolicy(userRepo *user_repository.UserRepo, env *dotenv.Env) *VerificationPolicy {
+	activityLimit, err := strconv.Atoi(env.UNVERIFIED_ACTIVITY_LIMIT)
+	if err != nil {
+		activityLimit = DEFAULT_UNVERIFIED_ACTIVITY_LIMIT
+	}
+	allowUploads, _ := strconv.ParseBool(env.UNVERIFIED_UPLOADS_ALLOWED)
+
+	return &VerificationPolicy{
+		UserRepo:                userRepo,
+		MaxUnverifiedActivities: activityLimit,
+		AllowUploads:            allowUploads,
+	}
+}
+
+// CheckUpload rejects file uploads of unverified users.
+func (p *VerificationPolicy) CheckUpload(ctx context.Context, userId int) error {
+	if p.AllowUploads {
+		return nil
+	}
+
+	verified, err := p.UserRepo.IsEmailVerified(ctx, userId)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		return errors.Wrap(customErrors.ErrForbidden, "verify your email to upload files")
+	}
+	return nil
+}
+
+// ActivityLimit returns how many activities the user may keep, or nil if
+// they are verified or the limit is disabled. The activity repository enforces
+// it in the transaction that adds them.
+func (p *VerificationPolicy) ActivityLimit(ctx context.Context, userId int) (*int, error) {
+	if p.MaxUnverifiedActivities < 0 {
+		return nil, nil
+	}
+
+	verified, err := p.UserRepo.IsEmailVerified(ctx, userId)
+	if err != nil || verified {
+		return nil, err
+	}
+	limit := p.MaxUnverifiedActivities
+	return &limit, nil
+}
@@ -12,10 +12,14 @@ const (
 )
 
 var (
-	ErrNotFound     = pgx.ErrNoRows
-	ErrConflict     = errors.New("conflict")
-	ErrBadRequest   = errors.New("bad request")
-	ErrUnauthorized = errors.New("unauthorized")
+	ErrNotFound            = pgx.ErrNoRows
+	ErrConflict            = errors.New("conflict")
+	ErrBadRequest          = errors.New("bad request")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrUnprocessableEntity = errors.New("unprocessable entity")
+	ErrForbidden           = errors.New("forbidden")
+	ErrTooManyRequests     = errors.New("too many requests")
 )
 
 func GetPgErrCode(err error) string {
@@ -12,6 +12,23 @@ type Env struct {
 	AWS_S3_ID          string
 	AWS_S3_SECRET_KEY  string
 	AWS_S3_BUCKET_NAME string
+	IDEMPOTENCY_TTL    string
+	TRASH_RETENTION    string
+	ACCESS_TOKEN_TTL   string
+	REFRESH_TOKEN_TTL  string
+	PASSWORD_RESET_TTL string
+	PASSWORD_RESET_URL string
+	MAIL_OUTBOX_DIR    string
+
+	LOGIN_LOCKOUT_THRESHOLD string
+	LOGIN_LOCKOUT_DURATION  string
+	TRUSTED_PROXIES         string
+
+	EMAIL_VERIFICATION_TTL     string
+	EMAIL_VERIFICATION_URL     string
+	UNVERIFIED_ACTIVITY_LIMIT  string
+	UNVERIFIED_UPLOADS_ALLOWED string
+	TOTP_ENCRYPTION_KEY        string
 }
 
 func LoadEnv() (*Env, error) {
@@ -26,5 +43,22 @@ func LoadEnv() (*Env, error) {
 		AWS_S3_ID:          os.Getenv("S3_ID"),
 		AWS_S3_SECRET_KEY:  os.Getenv("S3_SECRET_KEY"),
 		AWS_S3_BUCKET_NAME: os.Getenv("S3_BUCKET_NAME"),
+		IDEMPOTENCY_TTL:    os.Getenv("IDEMPOTENCY_TTL"),
+		TRASH_RETENTION:    os.Getenv("TRASH_RETENTION"),
+		ACCESS_TOKEN_TTL:   os.Getenv("ACCESS_TOKEN_TTL"),
+		REFRESH_TOKEN_TTL:  os.Getenv("REFRESH_TOKEN_TTL"),
+		PASSWORD_RESET_TTL: os.Getenv("PASSWORD_RESET_TTL"),
+		PASSWORD_RESET_URL: os.Getenv("PASSWORD_RESET_URL"),
+		MAIL_OUTBOX_DIR:    os.Getenv("MAIL_OUTBOX_DIR"),
+
+		LOGIN_LOCKOUT_THRESHOLD: os.Getenv("LOGIN_LOCKOUT_THRESHOLD"),
+		LOGIN_LOCKOUT_DURATION:  os.Getenv("LOGIN_LOCKOUT_DURATION"),
+		TRUSTED_PROXIES:         os.Getenv("TRUSTED_PROXIES"),
+
+		EMAIL_VERIFICATION_TTL:     os.Getenv("EMAIL_VERIFICATION_TTL"),
+		EMAIL_VERIFICATION_URL:     os.Getenv("EMAIL_VERIFICATION_URL"),
+		UNVERIFIED_ACTIVITY_LIMIT:  os.Getenv("UNVERIFIED_ACTIVITY_LIMIT"),
+		UNVERIFIED_UPLOADS_ALLOWED: os.Getenv("UNVERIFIED_UPLOADS_ALLOWED"),
+		TOTP_ENCRYPTION_KEY:        os.Getenv("TOTP_ENCRYPTION_KEY"),
 	}, nil
 }
@@ -0,0 +1,30 @@
+package helper
+
+import (
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	HeaderETag    = "ETag"
+	HeaderIfMatch = "If-Match"
+)
+
+// ETag builds a strong entity tag from a row's updated_at, which Postgres
+// stores with microsecond precision.
+func ETag(updatedAt time.Time) string {
+	return `"` + strconv.FormatInt(updatedAt.UnixMicro(), 36) + `"`
+}
+
+// MatchesETag reports whether an If-Match header value accepts etag. Weak
+// tags never match because If-Match requires strong comparison.
+func MatchesETag(ifMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
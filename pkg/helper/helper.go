@@ -22,6 +22,13 @@ func DerefInt(i *int, fallback int) int {
 func DerefGeneric[T any](value interface{}, fallback T) T {
 	val := reflect.ValueOf(value)
 
+	if val.Kind() == reflect.Pointer {
+		if val.IsNil() {
+			return fallback
+		}
+		return val.Elem().Interface().(T)
+	}
+
 	if val.Kind() == reflect.Struct && val.NumField() >= 2 {
 		validField := val.Field(1) // Assume "Valid" is always the second field
 		if validField.Kind() == reflect.Bool && validField.Bool() {
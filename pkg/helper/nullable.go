@@ -0,0 +1,47 @@
+package helper
+
+import (
+	"encoding/json"
+	"reflect"
+)
+
+// Nullable tells apart a JSON field that was omitted (Set is false) from one
+// explicitly sent as null (Set is true and Value is nil).
+type Nullable[T any] struct {
+	Set   bool
+	Value *T
+}
+
+func (n *Nullable[T]) UnmarshalJSON(data []byte) error {
+	n.Set = true
+	if string(data) == "null" {
+		n.Value = nil
+		return nil
+	}
+
+	var value T
+	if err := json.Unmarshal(data, &value); err != nil {
+		return err
+	}
+	n.Value = &value
+	return nil
+}
+
+// IsNull reports whether the field was explicitly sent as null.
+func (n Nullable[T]) IsNull() bool {
+	return n.Set && n.Value == nil
+}
+
+// Or returns the new value when the field was sent and fallback otherwise.
+func (n Nullable[T]) Or(fallback *T) *T {
+	if n.Set {
+		return n.Value
+	}
+	return fallback
+}
+
+// NullableValue unwraps Nullable fields for the validator so the usual tags
+// apply to the inner pointer, the same way they do for plain pointer fields.
+func NullableValue(field reflect.Value) interface{} {
+	return field.FieldByName("Value").Interface()
+}
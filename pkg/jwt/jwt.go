@@ -1,23 +1,36 @@
 package jwt
 
 import (
+	"fit-byte/pkg/randtoken"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
 	"github.com/pkg/errors"
 )
 
+// JWTClaim identifies the user. The token id (jti) allows revoking a single
+// token and Version is compared with the user's token version to revoke all
+// tokens issued before a "log out everywhere".
 type JWTClaim struct {
-	ID int
+	ID      int
+	Version int `json:"ver"`
 	jwt.RegisteredClaims
 }
 
-func CreateToken(id int, secret string) (string, error) {
+// CreateToken signs an access token for the user that expires after ttl.
+func CreateToken(id int, version int, secret string, ttl time.Duration) (string, error) {
+	jti, err := randtoken.Generate(16)
+	if err != nil {
+		return "", err
+	}
+
 	secretByte := []byte(secret)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &JWTClaim{
-		ID: id,
+		ID:      id,
+		Version: version,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(8 * time.Hour)),
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(ttl)),
 		},
 	})
 
@@ -46,7 +59,7 @@ func ClaimToken(token string, secret string) (*JWTClaim, error) {
 	}
 
 	claim, ok := jwtToken.Claims.(*JWTClaim)
-	if !ok {
+	if !ok || claim.RegisteredClaims.ID == "" {
 		return nil, errors.New("Invalid token")
 	}
 	return claim, nil
@@ -0,0 +1,72 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer delivers transactional emails. Implementations for a real mail
+// provider only need to satisfy this interface.
+type Mailer interface {
+	Send(ctx context.Context, message Message) error
+}
+
+// LogMailer writes emails to the log, for local development.
+type LogMailer struct {
+	Log *logrus.Logger
+}
+
+func NewLogMailer(log *logrus.Logger) *LogMailer {
+	return &LogMailer{
+		Log: log,
+	}
+}
+
+func (m *LogMailer) Send(_ context.Context, message Message) error {
+	m.Log.WithFields(logrus.Fields{
+		"to":      message.To,
+		"subject": message.Subject,
+	}).Info(message.Body)
+	return nil
+}
+
+// OutboxMailer writes every email as a .eml file into Dir.
+type OutboxMailer struct {
+	Dir string
+}
+
+func NewOutboxMailer(dir string) (*OutboxMailer, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, errors.Wrap(err, "failed to create outbox")
+	}
+	return &OutboxMailer{
+		Dir: dir,
+	}, nil
+}
+
+func (m *OutboxMailer) Send(_ context.Context, message Message) error {
+	now := time.Now().UTC()
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(message.To)
+	name := fmt.Sprintf("%s-%s.eml", now.Format("20060102T150405.000000000"), recipient)
+
+	content := fmt.Sprintf("To: %s\r\nSubject: %s\r\nDate: %s\r\n\r\n%s\r\n",
+		message.To, message.Subject, now.Format(time.RFC1123Z), message.Body)
+
+	if err := os.WriteFile(filepath.Join(m.Dir, name), []byte(content), 0o600); err != nil {
+		return errors.Wrap(err, "failed to write email to outbox")
+	}
+	return nil
+}
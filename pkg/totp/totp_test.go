@@ -0,0 +1,75 @@
+package totp
+
+import (
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 key of the RFC 6238 test vectors, "12345678901234567890",
+// in base32.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+// The RFC lists 8 digit codes; the 6 digit codes are their last 6 digits.
+var rfcVectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestCodeMatchesRFC6238(t *testing.T) {
+	for _, v := range rfcVectors {
+		got, err := Code(rfcSecret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", v.unix, err)
+		}
+		if got != v.code {
+			t.Errorf("Code at %d = %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestValidateAcceptsAdjacentSteps(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	current := Step(now)
+
+	for _, offset := range []int64{-1, 0, 1} {
+		code, err := Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		step, ok := Validate(rfcSecret, code, now)
+		if !ok {
+			t.Errorf("Validate rejected the code of step %+d", offset)
+			continue
+		}
+		if step != current+offset {
+			t.Errorf("Validate matched step %d, want %d", step, current+offset)
+		}
+	}
+
+	for _, offset := range []int64{-2, 2} {
+		code, err := Code(rfcSecret, current+offset)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("Validate accepted the code of step %+d", offset)
+		}
+	}
+}
+
+func TestValidateRejectsCodesOfOtherLengths(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	for _, code := range []string{"", "28708", "4287082", "94287082", "2870820"} {
+		if _, ok := Validate(rfcSecret, code, now); ok {
+			t.Errorf("Validate accepted %q", code)
+		}
+	}
+}
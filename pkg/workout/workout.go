@@ -0,0 +1,289 @@
+package workout
+
+import (
+	"encoding/xml"
+	"io"
+	"math"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	FormatGPX = "gpx"
+	FormatTCX = "tcx"
+
+	earthRadiusMeters = 6371000
+	// climbs smaller than this are GPS or barometer noise
+	elevationNoiseMeters = 3
+)
+
+var ErrInvalidWorkout = errors.New("invalid workout file")
+
+// Workout is the summary of a recorded GPS workout. Distance and elevation
+// gain are nil when the file has too few positions or elevations to measure
+// them, as for a treadmill run or a pool swim.
+type Workout struct {
+	Sport               string
+	StartTime           time.Time
+	ElapsedTime         time.Duration
+	DistanceMeters      *float64
+	ElevationGainMeters *float64
+}
+
+type trackPoint struct {
+	Lat         float64
+	Lon         float64
+	HasPosition bool
+	Elevation   *float64
+	Time        *time.Time
+}
+
+// FormatFromFilename returns the workout format for a .gpx or .tcx file name.
+func FormatFromFilename(filename string) (string, bool) {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".gpx":
+		return FormatGPX, true
+	case ".tcx":
+		return FormatTCX, true
+	default:
+		return "", false
+	}
+}
+
+func Parse(r io.Reader, format string) (*Workout, error) {
+	switch format {
+	case FormatGPX:
+		return parseGPX(r)
+	case FormatTCX:
+		return parseTCX(r)
+	default:
+		return nil, errors.Wrap(ErrInvalidWorkout, "unsupported format")
+	}
+}
+
+type gpxFile struct {
+	Metadata struct {
+		Time *time.Time `xml:"time"`
+	} `xml:"metadata"`
+	Tracks []struct {
+		Type     string `xml:"type"`
+		Segments []struct {
+			Points []struct {
+				Lat       float64    `xml:"lat,attr"`
+				Lon       float64    `xml:"lon,attr"`
+				Elevation *float64   `xml:"ele"`
+				Time      *time.Time `xml:"time"`
+			} `xml:"trkpt"`
+		} `xml:"trkseg"`
+	} `xml:"trk"`
+}
+
+func parseGPX(r io.Reader) (*Workout, error) {
+	var file gpxFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errors.Wrap(ErrInvalidWorkout, err.Error())
+	}
+
+	var workout Workout
+	var segments [][]trackPoint
+	for _, track := range file.Tracks {
+		if workout.Sport == "" {
+			workout.Sport = strings.TrimSpace(track.Type)
+		}
+		for _, segment := range track.Segments {
+			points := make([]trackPoint, 0, len(segment.Points))
+			for _, point := range segment.Points {
+				points = append(points, trackPoint{
+					Lat:         point.Lat,
+					Lon:         point.Lon,
+					HasPosition: true,
+					Elevation:   point.Elevation,
+					Time:        point.Time,
+				})
+			}
+			segments = append(segments, points)
+		}
+	}
+
+	workout.DistanceMeters, workout.ElevationGainMeters = measure(segments)
+
+	start, end := timeRange(segments)
+	if start == nil {
+		start = file.Metadata.Time
+	}
+	if start == nil {
+		return nil, errors.Wrap(ErrInvalidWorkout, "workout has no timestamps")
+	}
+	workout.StartTime = *start
+	if end != nil {
+		workout.ElapsedTime = end.Sub(*start)
+	}
+
+	return &workout, nil
+}
+
+type tcxFile struct {
+	Activities []struct {
+		Sport string     `xml:"Sport,attr"`
+		Id    *time.Time `xml:"Id"`
+		Laps  []struct {
+			StartTime        *time.Time `xml:"StartTime,attr"`
+			TotalTimeSeconds float64    `xml:"TotalTimeSeconds"`
+			DistanceMeters   float64    `xml:"DistanceMeters"`
+			Tracks           []struct {
+				Points []struct {
+					Time     *time.Time `xml:"Time"`
+					Position *struct {
+						Lat float64 `xml:"LatitudeDegrees"`
+						Lon float64 `xml:"LongitudeDegrees"`
+					} `xml:"Position"`
+					Altitude *float64 `xml:"AltitudeMeters"`
+				} `xml:"Trackpoint"`
+			} `xml:"Track"`
+		} `xml:"Lap"`
+	} `xml:"Activities>Activity"`
+}
+
+func parseTCX(r io.Reader) (*Workout, error) {
+	var file tcxFile
+	if err := xml.NewDecoder(r).Decode(&file); err != nil {
+		return nil, errors.Wrap(ErrInvalidWorkout, err.Error())
+	}
+
+	if len(file.Activities) == 0 {
+		return nil, errors.Wrap(ErrInvalidWorkout, "file has no activity")
+	}
+	// multi-sport files are rare; only the first activity is imported
+	activity := file.Activities[0]
+
+	workout := Workout{Sport: activity.Sport}
+	var start *time.Time
+	var lapSeconds, lapDistance float64
+	var segments [][]trackPoint
+	for _, lap := range activity.Laps {
+		if start == nil {
+			start = lap.StartTime
+		}
+		lapSeconds += lap.TotalTimeSeconds
+		lapDistance += lap.DistanceMeters
+		for _, track := range lap.Tracks {
+			points := make([]trackPoint, 0, len(track.Points))
+			for _, point := range track.Points {
+				p := trackPoint{
+					Elevation: point.Altitude,
+					Time:      point.Time,
+				}
+				if point.Position != nil {
+					p.Lat = point.Position.Lat
+					p.Lon = point.Position.Lon
+					p.HasPosition = true
+				}
+				points = append(points, p)
+			}
+			segments = append(segments, points)
+		}
+	}
+
+	distance, elevationGain := measure(segments)
+	workout.DistanceMeters = distance
+	if lapDistance > 0 {
+		workout.DistanceMeters = &lapDistance
+	}
+	workout.ElevationGainMeters = elevationGain
+
+	pointStart, pointEnd := timeRange(segments)
+	if start == nil {
+		start = pointStart
+	}
+	if start == nil {
+		start = activity.Id
+	}
+	if start == nil {
+		return nil, errors.Wrap(ErrInvalidWorkout, "workout has no timestamps")
+	}
+	workout.StartTime = *start
+
+	workout.ElapsedTime = time.Duration(lapSeconds * float64(time.Second))
+	if workout.ElapsedTime == 0 && pointEnd != nil {
+		workout.ElapsedTime = pointEnd.Sub(*start)
+	}
+
+	return &workout, nil
+}
+
+// measure sums the distance between consecutive positions and the climbs
+// within each segment. A climb only counts once it exceeds
+// elevationNoiseMeters, so jitter on flat ground adds nothing. Either value
+// is nil when no segment has two points to measure it from.
+func measure(segments [][]trackPoint) (*float64, *float64) {
+	var distance, elevationGain float64
+	var hasDistance, hasElevation bool
+	for _, points := range segments {
+		var prevPosition *trackPoint
+		var base *float64
+		for i := range points {
+			point := &points[i]
+			if point.HasPosition {
+				if prevPosition != nil {
+					distance += haversine(prevPosition.Lat, prevPosition.Lon, point.Lat, point.Lon)
+					hasDistance = true
+				}
+				prevPosition = point
+			}
+			if point.Elevation == nil {
+				continue
+			}
+			if base == nil {
+				base = point.Elevation
+				continue
+			}
+			hasElevation = true
+			if climb := *point.Elevation - *base; climb >= elevationNoiseMeters {
+				elevationGain += climb
+				base = point.Elevation
+			} else if climb < 0 {
+				base = point.Elevation
+			}
+		}
+	}
+	return optional(hasDistance, distance), optional(hasElevation, elevationGain)
+}
+
+func optional(ok bool, value float64) *float64 {
+	if !ok {
+		return nil
+	}
+	return &value
+}
+
+func timeRange(segments [][]trackPoint) (*time.Time, *time.Time) {
+	var start, end *time.Time
+	for _, points := range segments {
+		for _, point := range points {
+			if point.Time == nil {
+				continue
+			}
+			if start == nil || point.Time.Before(*start) {
+				start = point.Time
+			}
+			if end == nil || point.Time.After(*end) {
+				end = point.Time
+			}
+		}
+	}
+	return start, end
+}
+
+func haversine(lat1, lon1, lat2, lon2 float64) float64 {
+	phi1 := lat1 * math.Pi / 180
+	phi2 := lat2 * math.Pi / 180
+	deltaPhi := (lat2 - lat1) * math.Pi / 180
+	deltaLambda := (lon2 - lon1) * math.Pi / 180
+
+	a := math.Sin(deltaPhi/2)*math.Sin(deltaPhi/2) +
+		math.Cos(phi1)*math.Cos(phi2)*math.Sin(deltaLambda/2)*math.Sin(deltaLambda/2)
+	return earthRadiusMeters * 2 * math.Atan2(math.Sqrt(a), math.Sqrt(1-a))
+}
@@ -0,0 +1,103 @@
+package workout
+
+import (
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func parseFixture(t *testing.T, name string) *Workout {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	format, ok := FormatFromFilename(name)
+	if !ok {
+		t.Fatalf("FormatFromFilename(%q) is not supported", name)
+	}
+	workout, err := Parse(file, format)
+	if err != nil {
+		t.Fatalf("Parse(%q): %v", name, err)
+	}
+	return workout
+}
+
+func assertMeters(t *testing.T, field string, got *float64, want *float64) {
+	t.Helper()
+
+	switch {
+	case got == nil && want == nil:
+	case got == nil || want == nil:
+		t.Errorf("%s = %v, want %v", field, got, want)
+	case math.Abs(*got-*want) > 0.01:
+		t.Errorf("%s = %.2f, want %.2f", field, *got, *want)
+	}
+}
+
+func meters(m float64) *float64 {
+	return &m
+}
+
+func TestParse(t *testing.T) {
+	// 0.001 degrees of latitude on a sphere of earthRadiusMeters
+	const milliDegree = earthRadiusMeters * math.Pi / 180 / 1000
+
+	tests := []struct {
+		file          string
+		sport         string
+		start         time.Time
+		elapsed       time.Duration
+		distance      *float64
+		elevationGain *float64
+	}{
+		{
+			file:    "run.gpx",
+			sport:   "running",
+			start:   time.Date(2024, 5, 1, 6, 0, 0, 0, time.UTC),
+			elapsed: 3 * time.Minute,
+			// three steps of 0.001 degrees
+			distance: meters(3 * milliDegree),
+			// 10 -> 11 is noise, 10 -> 15 climbs, 15 -> 14 descends
+			elevationGain: meters(5),
+		},
+		{
+			file:    "ride.tcx",
+			sport:   "Biking",
+			start:   time.Date(2024, 5, 2, 7, 0, 0, 0, time.UTC),
+			elapsed: 30 * time.Minute,
+			// the lap distance wins over the track points
+			distance:      meters(12000.5),
+			elevationGain: meters(0),
+		},
+		{
+			file:    "treadmill.tcx",
+			sport:   "Running",
+			start:   time.Date(2024, 5, 3, 18, 0, 0, 0, time.UTC),
+			elapsed: 20 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.file, func(t *testing.T) {
+			workout := parseFixture(t, tt.file)
+
+			if workout.Sport != tt.sport {
+				t.Errorf("Sport = %q, want %q", workout.Sport, tt.sport)
+			}
+			if !workout.StartTime.Equal(tt.start) {
+				t.Errorf("StartTime = %v, want %v", workout.StartTime, tt.start)
+			}
+			if workout.ElapsedTime != tt.elapsed {
+				t.Errorf("ElapsedTime = %v, want %v", workout.ElapsedTime, tt.elapsed)
+			}
+			assertMeters(t, "DistanceMeters", workout.DistanceMeters, tt.distance)
+			assertMeters(t, "ElevationGainMeters", workout.ElevationGainMeters, tt.elevationGain)
+		})
+	}
+}